@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+// runCheckOptimal reports the gap between a heuristic path finder and the
+// exact minimum number of turns (time-expanded max flow). Both keep out of
+// the map's ##avoid rooms.
+func runCheckOptimal(args []string) {
+	fs := flag.NewFlagSet("check-optimal", flag.ExitOnError)
+	algo := fs.String("algo", path.AlgoEdmondsKarp, "heuristic to check: "+strings.Join(path.Names(), ", "))
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fmt.Println(usage)
+		os.Exit(0)
+	}
+	res, err := parser.ParseFile(fs.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+
+	// ##avoid only removes rooms, which the exact solver honours; waypoints
+	// are merged in afterwards, so its answer would no longer be an optimum
+	switch {
+	case len(res.Groups) > 0:
+		fmt.Println("ERROR: check-optimal does not apply to maps with ##group")
+		os.Exit(0)
+	case len(res.Via) > 0:
+		fmt.Println("ERROR: check-optimal does not apply to maps with ##via")
+		os.Exit(0)
+	}
+	opts := path.Options{Ants: res.Ants, Avoid: res.Avoid}
+	heur, opt, err := path.CheckOptimal(context.Background(), *algo, res.Graph, opts)
+	if errors.Is(err, path.ErrNoPath) {
+		fmt.Println("ERROR: invalid data format, no path found")
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+	gap := heur.Turns - opt.Turns
+	fmt.Printf("heuristic (%s): %d turns\n", heur.Algo, heur.Turns)
+	fmt.Printf("optimal (exact): %d turns\n", opt.Turns)
+	fmt.Printf("gap: %d turns (%.1f%%)\n", gap, 100*float64(gap)/float64(opt.Turns))
+}
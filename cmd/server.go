@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"lem-in/internal/antfarm"
+	"lem-in/internal/path"
 )
 
 func handleHome(w http.ResponseWriter, r *http.Request) {
@@ -81,6 +87,7 @@ I5-end`
 	tmpl := template.Must(template.ParseFiles("cmd/visualizer/templates/index.html"))
 	tmpl.Execute(w, map[string]interface{}{
 		"DefaultInput": defaultInput,
+		"Algos":        antfarm.Algorithms(),
 	})
 }
 
@@ -110,16 +117,53 @@ func handleVisualize(w http.ResponseWriter, r *http.Request) {
 		renderError(w, input, "Missing end room")
 		return
 	}
+	if len(farm.Groups) > 0 {
+		// the animation follows one colony; run lem-in for the joint schedule
+		renderError(w, input, "Ant groups (##group) are not supported by the visualizer")
+		return
+	}
+
+	// Every step of the request runs within the configured time budget. Path
+	// finding may spend three quarters of it, so a partial result still
+	// leaves time to schedule and draw it.
+	ctx, cancel := context.WithTimeout(r.Context(), solveTimeout)
+	defer cancel()
+	findCtx, cancelFind := context.WithTimeout(ctx, solveTimeout*3/4)
+	defer cancelFind()
 
-	// Get all room-disjoint paths
-	paths := antfarm.Suurballe(farm)
-	if len(paths) == 0 {
+	// the request solves in pooled max-flow buffers
+	ws := workspaces.Get().(*path.Workspace)
+	defer workspaces.Put(ws)
+
+	algo := r.FormValue("algo")
+	// Get the room-disjoint path set that needs the fewest turns
+	sel, err := antfarm.SelectPaths(findCtx, farm, algo, ws)
+	if errors.Is(err, path.ErrNoPath) {
 		renderError(w, input, "No valid paths found from start to end")
 		return
 	}
-
-	// Simulate movements
-	movements := antfarm.Schedule(farm, paths)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		renderError(w, input, fmt.Sprintf("No path found within the %v time budget", solveTimeout))
+		return
+	}
+	if err != nil {
+		renderError(w, input, err.Error())
+		return
+	}
+	// Simulate movements with the CLI's scheduler (it sorts sel.Paths, so
+	// split them afterwards to keep the path indexes in step), within the
+	// same budget: a half-finished schedule is not worth animating
+	sched, err := sel.ScheduleContext(ctx, farm.Ants, farm.Graph)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		renderError(w, input, fmt.Sprintf("Scheduling did not finish within the %v time budget", solveTimeout))
+		return
+	}
+	if err != nil {
+		renderError(w, input, err.Error())
+		return
+	}
+	movements := antfarm.Positions(sched)
+	paths := antfarm.Split(sel.Paths)
 
 	// Visualization coordinates
 	scale := 50
@@ -151,11 +195,13 @@ func handleVisualize(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	tunnelsJSON := []map[string]int{}
+	tunnelsJSON := []map[string]interface{}{}
 	for _, room := range farm.Graph.Rooms {
 		for _, link := range room.Links {
 			if room.Name < link.Name {
-				tunnelsJSON = append(tunnelsJSON, map[string]int{
+				tunnelsJSON = append(tunnelsJSON, map[string]interface{}{
+					"a":  room.Name,
+					"b":  link.Name,
 					"x1": room.X*scale + offsetX,
 					"y1": height - (room.Y*scale + offsetY),
 					"x2": link.X*scale + offsetX,
@@ -165,11 +211,44 @@ func handleVisualize(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// min cut: rooms and tunnels that limit throughput (none if the budget
+	// runs out first; the extras below then show what they have)
+	cut, err := ws.MinCutContext(ctx, farm.Graph)
+	if err != nil {
+		cut = &path.Cut{}
+	}
+	cutNames := append([]string{}, cut.Rooms...)
+	for _, l := range cut.Links {
+		cutNames = append(cutNames, l[0]+"-"+l[1])
+	}
+
+	// k shortest simple paths (Yen), listed for highlighting
+	kPaths, _ := path.KShortestContext(ctx, farm.Graph, 10)
+	shortestJSON := [][]string{}
+	for _, p := range kPaths {
+		names := []string{}
+		for _, r := range p.Rooms {
+			names = append(names, r.Name)
+		}
+		shortestJSON = append(shortestJSON, names)
+	}
+
+	// max-flow augmentations, for stepping through in the page, on request
+	// only: an Edmonds–Karp reference whichever finder chose the paths
+	var traceJSON []byte
+	if r.FormValue("trace") != "" {
+		trace, _ := path.TraceFlow(ctx, farm.Graph, path.Options{Workspace: ws})
+		traceJSON, _ = json.Marshal(trace)
+	}
+	trimmed := ctx.Err() != nil
+
 	// Marshal to JSON
 	movementsJSON, _ := json.Marshal(movements)
 	roomsJSONStr, _ := json.Marshal(roomsJSON)
 	tunnelsJSONStr, _ := json.Marshal(tunnelsJSON)
 	roomPosJSON, _ := json.Marshal(roomPositions)
+	shortestJSONStr, _ := json.Marshal(shortestJSON)
+	cutJSON, _ := json.Marshal(cut)
 
 	// Prepare path strings for display
 	pathStrings := []string{}
@@ -183,17 +262,69 @@ func handleVisualize(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Flow levels compared by the path selection
+	flowLevels := []string{}
+	for _, c := range sel.Candidates {
+		label := fmt.Sprintf("%d path(s): %d turns", c.Flow, c.Turns)
+		if c.Flow == sel.Flow {
+			label += " ✓"
+		}
+		flowLevels = append(flowLevels, label)
+	}
+
+	// lower bounds on the turn count, to judge the result
+	bounds := path.LowerBoundsFrom(farm.Graph, farm.Ants, cut)
+	gap := 0
+	if bounds != nil {
+		gap = bounds.Gap(len(movements))
+	}
+
+	// portfolio: winner and how long each configuration ran
+	portfolio := []string{}
+	if rep := sel.Portfolio; rep != nil {
+		for _, run := range rep.Runs {
+			label := fmt.Sprintf("%v: %d turns in %v", run.Config, run.Turns, run.Elapsed.Round(time.Microsecond))
+			switch {
+			case run.Err != nil:
+				label = fmt.Sprintf("%v: %v", run.Config, run.Err)
+			case run.Optimal:
+				label += " (optimal)"
+			case run.Cancelled:
+				label += " (cancelled)"
+			}
+			if run.Config == rep.Winner {
+				label += " ✓"
+			}
+			portfolio = append(portfolio, label)
+		}
+	}
+
 	tmpl := template.Must(template.ParseFiles("cmd/visualizer/templates/visualize.html"))
 	tmpl.Execute(w, map[string]interface{}{
 		"Input":         input,
+		"Algo":          sel.Algo,
+		"Settings":      sel.Settings,
+		"Expanded":      sel.Expanded,
+		"Partial":       sel.Partial,
+		"Trimmed":       trimmed,
+		"Timeout":       solveTimeout,
 		"Ants":          farm.Ants,
 		"RoomCount":     len(farm.Graph.Rooms),
 		"TunnelCount":   len(tunnelsJSON),
+		"Turns":         len(movements),
+		"Bounds":        bounds,
+		"Gap":           gap,
 		"Movements":     template.JS(movementsJSON),
 		"Rooms":         template.JS(roomsJSONStr),
 		"Tunnels":       template.JS(tunnelsJSONStr),
 		"RoomPositions": template.JS(roomPosJSON),
+		"Shortest":      template.JS(shortestJSONStr),
+		"Cut":           template.JS(cutJSON),
+		"CutNames":      strings.Join(cutNames, ", "),
+		"Trace":         template.JS(traceJSON),
 		"Paths":         pathStrings,
+		"FlowLevels":    flowLevels,
+		"Portfolio":     portfolio,
 	})
 }
 
@@ -205,7 +336,18 @@ func renderError(w http.ResponseWriter, input, errorMsg string) {
 	})
 }
 
+// workspaces lends max-flow buffers to requests so repeated solves of
+// similar-sized maps do not allocate a fresh residual graph each time.
+var workspaces = sync.Pool{New: func() any { return path.NewWorkspace() }}
+
+// solveTimeout bounds each /visualize request (-timeout flag): path finding,
+// scheduling and the extras drawn alongside.
+var solveTimeout = 10 * time.Second
+
 func main() {
+	flag.DurationVar(&solveTimeout, "timeout", solveTimeout, "time budget per request")
+	flag.Parse()
+
 	http.HandleFunc("/", handleHome)
 	http.HandleFunc("/visualize", handleVisualize)
 
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lem-in/internal/antfarm"
+)
+
+var movementsRe = regexp.MustCompile("const movements = JSON.parse\\(`(.*)`\\);")
+
+var chdirOnce sync.Once
+
+// toRepoRoot moves to the repository root, where the templates and the
+// example maps are addressed from.
+func toRepoRoot(tb testing.TB) {
+	var err error
+	chdirOnce.Do(func() { err = os.Chdir("..") })
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// The visualizer must animate exactly the moves ./lem-in prints.
+func TestVisualizeMatchesCLI(t *testing.T) {
+	toRepoRoot(t)
+	bin := filepath.Join(t.TempDir(), "lem-in")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building the CLI: %v\n%s", err, out)
+	}
+	files, err := filepath.Glob("example*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no example maps (%v)", err)
+	}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			out, err := exec.Command(bin, file).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the moves follow the echoed map and a blank line
+			_, text, _ := strings.Cut(string(out), "\n\n")
+			var cli [][]string
+			for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+				cli = append(cli, strings.Fields(line))
+			}
+
+			if got := visualizedMoves(t, file); !reflect.DeepEqual(got, cli) {
+				t.Errorf("visualizer moves differ from the CLI\nvisualizer: %v\ncli:        %v", got, cli)
+			}
+		})
+	}
+}
+
+// visualizedMoves posts the map to /visualize and reads back the movements
+// the page animates, as "L<ant>-<room>" tokens per turn.
+func visualizedMoves(t *testing.T, file string) [][]string {
+	t.Helper()
+	input, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"input": {string(input)}}
+	req := httptest.NewRequest(http.MethodPost, "/visualize", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handleVisualize(rec, req)
+
+	m := movementsRe.FindStringSubmatch(rec.Body.String())
+	if m == nil {
+		t.Fatalf("no movements in the page:\n%s", rec.Body.String())
+	}
+	// the JSON sits JS-escaped inside a string literal
+	var raw string
+	if err := json.Unmarshal([]byte(`"`+m[1]+`"`), &raw); err != nil {
+		t.Fatal(err)
+	}
+	var turns [][]antfarm.AntPosition
+	if err := json.Unmarshal([]byte(raw), &turns); err != nil {
+		t.Fatal(err)
+	}
+	moves := make([][]string, len(turns))
+	for i, turn := range turns {
+		for _, p := range turn {
+			moves[i] = append(moves[i], fmt.Sprintf("L%d-%s", p.AntID, p.Room))
+		}
+	}
+	return moves
+}
+
+// The max-flow trace is computed only when the form asks for it.
+func TestVisualizeTraceIsOptional(t *testing.T) {
+	toRepoRoot(t)
+	input, err := os.ReadFile("example01.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, withTrace := range []bool{false, true} {
+		form := url.Values{"input": {string(input)}}
+		if withTrace {
+			form.Set("trace", "1")
+		}
+		req := httptest.NewRequest(http.MethodPost, "/visualize", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleVisualize(rec, req)
+		body := rec.Body.String()
+		if got := strings.Contains(body, "Reference Trace"); got != withTrace {
+			t.Errorf("trace requested %v, panel shown %v", withTrace, got)
+		}
+		if got := !strings.Contains(body, "const trace = null;"); got != withTrace {
+			t.Errorf("trace requested %v, trace data sent %v", withTrace, got)
+		}
+	}
+}
+
+// A spent budget stops the request instead of letting it run on.
+func TestVisualizeHonoursTimeout(t *testing.T) {
+	toRepoRoot(t)
+	defer func(d time.Duration) { solveTimeout = d }(solveTimeout)
+	solveTimeout = time.Nanosecond
+
+	input, err := os.ReadFile("example05.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"input": {string(input)}}
+	req := httptest.NewRequest(http.MethodPost, "/visualize", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handleVisualize(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "time budget") || movementsRe.MatchString(body) {
+		t.Errorf("expected a time budget error page, got:\n%s", body)
+	}
+}
+
+// A group map gets an error page, not an animation of its main colony.
+func TestVisualizeRefusesGroups(t *testing.T) {
+	toRepoRoot(t)
+	input := "1\n##group east 1 w x\n##start\ns 0 0\n##end\ne 2 0\nw 0 1\nx 2 1\ns-e\nw-x\n"
+	form := url.Values{"input": {input}}
+	req := httptest.NewRequest(http.MethodPost, "/visualize", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handleVisualize(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "##group") || movementsRe.MatchString(body) {
+		t.Errorf("expected a group error page, got:\n%s", body)
+	}
+}
+
+// BenchmarkVisualize measures a whole /visualize request: parsing, path
+// finding, scheduling, the min cut, the bounds and the trace, and rendering.
+func BenchmarkVisualize(b *testing.B) {
+	toRepoRoot(b)
+	input, err := os.ReadFile("example05.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	body := url.Values{"input": {string(input)}}.Encode()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/visualize", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handleVisualize(httptest.NewRecorder(), req)
+	}
+}
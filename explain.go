@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+// writeExplain reports which rooms and tunnels limit throughput (the minimum
+// cut of the room-disjoint flow network) and how the chosen paths compare.
+func writeExplain(w io.Writer, res *parser.Result, sel *path.Selection) {
+	cut := path.MinCut(res.Graph)
+	fmt.Fprintf(w, "max flow: %d room-disjoint paths (%s)\n", cut.Flow, res.Graph.Start.Name+" -> "+res.Graph.End.Name)
+	fmt.Fprintf(w, "chosen: %d paths, %d turns for %d ants (%s)\n", sel.Flow, sel.Turns, res.Ants, sel.Algo)
+	fmt.Fprintln(w, "minimum cut (every path crosses one of these):")
+	for _, r := range cut.Rooms {
+		fmt.Fprintf(w, "  room   %s\n", r)
+	}
+	for _, l := range cut.Links {
+		fmt.Fprintf(w, "  tunnel %s-%s\n", l[0], l[1])
+	}
+	var parts []string
+	for _, r := range cut.Rooms {
+		parts = append(parts, r)
+	}
+	for _, l := range cut.Links {
+		parts = append(parts, l[0]+"-"+l[1])
+	}
+	fmt.Fprintf(w, "a new tunnel raises the flow only if it bypasses %s\n", strings.Join(parts, ", "))
+}
+
+// writeStats compares the turn count with the lower bounds from the graph,
+// so a heuristic that is clearly off shows up as a large gap.
+func writeStats(w io.Writer, res *parser.Result, turns int) {
+	b := path.LowerBounds(res.Graph, res.Ants)
+	fmt.Fprintf(w, "turns: %d for %d ants\n", turns, res.Ants)
+	fmt.Fprintf(w, "lower bound (shortest path): %d\n", b.ShortestPath)
+	fmt.Fprintf(w, "lower bound (min cut of %d, path lengths): %d\n", b.CutSize, b.Cut)
+	gap := b.Gap(turns)
+	if gap == 0 {
+		fmt.Fprintln(w, "gap: 0 turns (optimal)")
+		return
+	}
+	fmt.Fprintf(w, "gap: %d turns (%.1f%% above the bound)\n", gap, 100*float64(gap)/float64(b.Best()))
+}
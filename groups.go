@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+// runGroups solves a map with several ant groups (##group lines) jointly and
+// prints the shared schedule. The chosen routes are reported on stderr.
+func runGroups(ctx context.Context, res *parser.Result, algo string) {
+	plan, err := path.SolveGroups(ctx, algo, res.Graph, res.AntGroups())
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(0)
+	}
+	fmt.Fprintf(os.Stderr, "%d groups, %d turns (%s)\n", len(plan.Routes), plan.Schedule.Len(), plan.Strategy)
+	id := 1
+	for _, r := range plan.Routes {
+		fmt.Fprintf(os.Stderr, "group %s: ants L%d..L%d, %s -> %s, %d paths\n",
+			r.Group.Name, id, id+r.Group.Ants-1, r.Group.Start.Name, r.Group.End.Name, len(r.Paths))
+		id += r.Group.Ants
+	}
+	plan.Schedule.WriteText(os.Stdout)
+}
@@ -2,11 +2,13 @@ package antfarm
 
 import (
 	"bufio"
+	"context"
 	"strings"
 
 	"lem-in/internal/model"
 	"lem-in/internal/parser"
 	"lem-in/internal/path"
+	"lem-in/internal/scheduler"
 )
 
 // Farm is a wrapper for parser.Result
@@ -25,14 +27,36 @@ func ParseInput(input string) (*Farm, error) {
 	return parser.Parse(scanner)
 }
 
-// Suurballe returns the set of room-disjoint paths from start to end
+// Algorithms lists the path finders SelectPaths accepts
+func Algorithms() []string {
+	return path.Names()
+}
+
+// SelectPaths returns the room-disjoint path set found by the named
+// path finder that needs the fewest turns for the farm's ants.
+// If ctx ends first the best set so far is returned with Partial set.
+// The map's ##avoid and ##via rooms constrain the paths. ws may be nil;
+// otherwise the flow-based finders solve in its buffers.
+func SelectPaths(ctx context.Context, farm *Farm, algo string, ws *path.Workspace) (*path.Selection, error) {
+	opts := path.Options{Ants: farm.Ants, Avoid: farm.Avoid, Via: farm.Via, Workspace: ws} // MaxPaths 0 = no limit
+	return path.Find(ctx, algo, farm.Graph, opts)
+}
+
+// Suurballe returns the room-disjoint paths of minimum total length
+// (min-cost flow), keeping the flow level that needs the fewest turns
 func Suurballe(farm *Farm) [][]*model.Path {
-	paths := path.MultiPath(farm.Graph, 0) // 0 = no limit
-	if len(paths) == 0 {
+	sel := path.BestMinCost(farm.Graph, farm.Ants, 0)
+	if sel == nil {
 		return nil
 	}
+	return Split(sel.Paths)
+}
 
-	// Return as a slice of slices with a single path each
+// Split returns paths as a slice of slices with a single path each
+func Split(paths []*model.Path) [][]*model.Path {
+	if len(paths) == 0 {
+		return nil
+	}
 	res := make([][]*model.Path, len(paths))
 	for i, p := range paths {
 		res[i] = []*model.Path{p}
@@ -40,116 +64,16 @@ func Suurballe(farm *Farm) [][]*model.Path {
 	return res
 }
 
-// Schedule simulates ant movements and returns movements per turn
-func Schedule(farm *Farm, paths [][]*model.Path) [][]AntPosition {
-	if farm.Ants <= 0 || len(paths) == 0 {
-		return nil
-	}
-
-	var allTurns [][]AntPosition
-
-	type AntState struct {
-		ID      int
-		PathIdx int
-		Pos     int
-	}
-
-	numPaths := len(paths)
-	ants := farm.Ants
-
-	// Assign ants to paths in round-robin
-	assigned := make([][]int, numPaths)
-	antID := 1
-	for antID <= ants {
-		for i := 0; i < numPaths && antID <= ants; i++ {
-			assigned[i] = append(assigned[i], antID)
-			antID++
-		}
-	}
-
-	occupied := make(map[string]int)
-	startName := farm.Graph.Start.Name
-	endName := farm.Graph.End.Name
-	antsState := make(map[int]*AntState)
-
-	waitQueues := assigned
-	finished := 0
-
-	for finished < ants {
-		var turnPositions []AntPosition
-
-		// Move existing ants along paths
-		for pi, pSlice := range paths {
-			for _, p := range pSlice {
-				posToAnt := make(map[int]int)
-				for id, st := range antsState {
-					if st.PathIdx == pi {
-						posToAnt[st.Pos] = id
-					}
-				}
-				for pos := p.Length - 1; pos >= 0; pos-- {
-					antID, ok := posToAnt[pos]
-					if !ok {
-						continue
-					}
-					as := antsState[antID]
-					curRoom := p.Rooms[as.Pos].Name
-					nextRoom := p.Rooms[as.Pos+1].Name
-
-					nextFree := (nextRoom == endName) || (occupied[nextRoom] == 0)
-					if nextFree {
-						if curRoom != startName && curRoom != endName {
-							occupied[curRoom] = 0
-						}
-						if nextRoom != startName && nextRoom != endName {
-							occupied[nextRoom] = antID
-						}
-						as.Pos++
-						turnPositions = append(turnPositions, AntPosition{
-							AntID:     antID,
-							Room:      nextRoom,
-							PathIndex: pi,
-						})
-						if nextRoom == endName {
-							finished++
-						}
-					}
-				}
-			}
-		}
-
-		// Start new ants if possible
-		for pi, pSlice := range paths {
-			for _, p := range pSlice {
-				if len(waitQueues[pi]) == 0 {
-					continue
-				}
-				firstRoom := p.Rooms[1].Name
-				if firstRoom == endName || occupied[firstRoom] == 0 {
-					newAnt := waitQueues[pi][0]
-					waitQueues[pi] = waitQueues[pi][1:]
-					antsState[newAnt] = &AntState{ID: newAnt, PathIdx: pi, Pos: 1}
-					if firstRoom != startName && firstRoom != endName {
-						occupied[firstRoom] = newAnt
-					}
-					turnPositions = append(turnPositions, AntPosition{
-						AntID:     newAnt,
-						Room:      firstRoom,
-						PathIndex: pi,
-					})
-					if firstRoom == endName {
-						finished++
-					}
-				}
-			}
-		}
-
-		if len(turnPositions) > 0 {
-			allTurns = append(allTurns, turnPositions)
-		} else {
-			break
+// Positions converts a schedule into the per-turn positions the visualizer
+// animates.
+func Positions(s *scheduler.Schedule) [][]AntPosition {
+	out := make([][]AntPosition, 0, s.Len())
+	for _, turn := range s.Turns {
+		positions := make([]AntPosition, len(turn))
+		for i, m := range turn {
+			positions[i] = AntPosition{AntID: m.Ant, Room: m.To, PathIndex: m.Path}
 		}
+		out = append(out, positions)
 	}
-
-	return allTurns
+	return out
 }
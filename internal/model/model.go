@@ -5,12 +5,23 @@ type Room struct {
 	X     int
 	Y     int
 	Links []*Room
+	Order int // position in which the room was added to its graph (0-based)
 }
 
 type Graph struct {
 	Rooms map[string]*Room
 	Start *Room
 	End   *Room
+	added int // rooms added so far, for Room.Order
+}
+
+// Group is one ant colony: Ants ants travelling from Start to End. A map can
+// hold several groups sharing the rooms.
+type Group struct {
+	Name  string
+	Ants  int
+	Start *Room
+	End   *Room
 }
 
 type Path struct {
@@ -26,7 +37,8 @@ func (g *Graph) AddRoom(name string, x, y int) *Room {
 	if r, ok := g.Rooms[name]; ok {
 		return r
 	}
-	r := &Room{Name: name, X: x, Y: y}
+	r := &Room{Name: name, X: x, Y: y, Order: g.added}
+	g.added++
 	g.Rooms[name] = r
 	return r
 }
@@ -47,3 +59,40 @@ func (g *Graph) AddLink(a, b string) bool {
 	rb.Links = append(rb.Links, ra)
 	return true
 }
+
+// RemoveLink deletes the tunnel a—b. It reports whether the tunnel existed.
+func (g *Graph) RemoveLink(a, b string) bool {
+	ra, aok := g.Rooms[a]
+	rb, bok := g.Rooms[b]
+	if !aok || !bok {
+		return false
+	}
+	var found bool
+	ra.Links, found = without(ra.Links, rb)
+	rb.Links, _ = without(rb.Links, ra)
+	return found
+}
+
+// RemoveRoom deletes a room and all its tunnels. It reports whether the room
+// existed. Start and End are not cleared.
+func (g *Graph) RemoveRoom(name string) bool {
+	r, ok := g.Rooms[name]
+	if !ok {
+		return false
+	}
+	for _, nb := range r.Links {
+		nb.Links, _ = without(nb.Links, r)
+	}
+	r.Links = nil
+	delete(g.Rooms, name)
+	return true
+}
+
+func without(rooms []*Room, r *Room) ([]*Room, bool) {
+	for i, x := range rooms {
+		if x == r {
+			return append(rooms[:i:i], rooms[i+1:]...), true
+		}
+	}
+	return rooms, false
+}
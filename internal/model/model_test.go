@@ -1 +1,34 @@
-package model
\ No newline at end of file
+package model
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	g := NewGraph()
+	g.AddRoom("a", 0, 0)
+	g.AddRoom("Lbad", 1, 0)
+	g.AddLink("a", "Lbad")
+
+	codes := map[string]bool{}
+	for _, is := range g.Validate() {
+		codes[is.Code] = true
+	}
+	for _, want := range []string{IssueMissingStart, IssueMissingEnd, IssueBadRoomName} {
+		if !codes[want] {
+			t.Errorf("missing issue %s in %v", want, g.Validate())
+		}
+	}
+
+	g.Start = g.Rooms["a"]
+	g.End = g.Rooms["a"]
+	if is := g.Validate(); len(is) == 0 || is[0].Code != IssueStartIsEnd {
+		t.Errorf("expected start-is-end first, got %v", is)
+	}
+
+	ok := NewGraph()
+	ok.Start = ok.AddRoom("s", 0, 0)
+	ok.End = ok.AddRoom("e", 1, 0)
+	ok.AddLink("s", "e")
+	if is := ok.Validate(); len(is) != 0 {
+		t.Errorf("valid graph reported %v", is)
+	}
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Issue codes reported by Validate.
+const (
+	IssueMissingStart = "missing-start"
+	IssueMissingEnd   = "missing-end"
+	IssueStartIsEnd   = "start-is-end"
+	IssueUnknownRoom  = "unknown-room"
+	IssueBadRoomName  = "bad-room-name"
+	IssueBadLink      = "bad-link"
+)
+
+// Issue is a single problem found by Validate.
+type Issue struct {
+	Code    string // one of the Issue* constants
+	Room    string // offending room, empty for graph-wide issues
+	Message string // human readable, same wording the parser reports
+}
+
+func (i Issue) String() string { return i.Message }
+
+// Validate applies the same rules as the parser to a graph built in code
+// (AddRoom/AddLink) and returns every issue found, in a stable order.
+// A nil result means the graph is usable by the path finders.
+func (g *Graph) Validate() []Issue {
+	var issues []Issue
+	add := func(code, room, format string, args ...interface{}) {
+		issues = append(issues, Issue{Code: code, Room: room, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if g.Start == nil {
+		add(IssueMissingStart, "", "missing start room")
+	} else if g.Rooms[g.Start.Name] != g.Start {
+		add(IssueUnknownRoom, g.Start.Name, "start room %s is not in the graph", g.Start.Name)
+	}
+	if g.End == nil {
+		add(IssueMissingEnd, "", "missing end room")
+	} else if g.Rooms[g.End.Name] != g.End {
+		add(IssueUnknownRoom, g.End.Name, "end room %s is not in the graph", g.End.Name)
+	}
+	if g.Start != nil && g.Start == g.End {
+		add(IssueStartIsEnd, g.Start.Name, "start and end are the same room")
+	}
+
+	// Sorted names keep the issue order deterministic.
+	names := make([]string, 0, len(g.Rooms))
+	for name := range g.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r := g.Rooms[name]
+		if !ValidRoomName(name) {
+			add(IssueBadRoomName, name, "invalid room name %q", name)
+		}
+		if r == nil || r.Name != name {
+			add(IssueUnknownRoom, name, "room %s is registered under a different name", name)
+			continue
+		}
+		seen := make(map[*Room]bool, len(r.Links))
+		for _, l := range r.Links {
+			switch {
+			case l == r:
+				add(IssueBadLink, name, "invalid link %s-%s", name, name)
+			case g.Rooms[l.Name] != l:
+				add(IssueUnknownRoom, l.Name, "link %s-%s points outside the graph", name, l.Name)
+			case seen[l]:
+				add(IssueBadLink, name, "duplicate link %s-%s", name, l.Name)
+			}
+			seen[l] = true
+		}
+	}
+	return issues
+}
+
+// ValidRoomName reports whether name is usable as a room name: non-empty,
+// no whitespace, and not starting with 'L' or '#'.
+func ValidRoomName(name string) bool {
+	if name == "" || name[0] == 'L' || name[0] == '#' {
+		return false
+	}
+	return !strings.ContainsAny(name, " \t\r\n")
+}
@@ -12,8 +12,11 @@ import (
 	"lem-in/internal/model"
 )
 
+// ErrInvalid is wrapped by every error Parse returns for a malformed map;
+// the rest of the message says what is wrong. Read errors are not wrapped.
+var ErrInvalid = errors.New("ERROR: invalid data format")
+
 var (
-	errInvalid = errors.New("ERROR: invalid data format")
 	roomLineRe = regexp.MustCompile(`^([^\s#L][^\s]*)\s+(-?\d+)\s+(-?\d+)$`)
 	linkLineRe = regexp.MustCompile(`^([^\s#L][^\s]*)-([^\s#L][^\s]*)$`)
 )
@@ -21,7 +24,26 @@ var (
 type Result struct {
 	Ants          int
 	Graph         *model.Graph
-	OriginalLines []string // sanitized lines to echo before moves
+	Groups        []model.Group // extra colonies from "##group <name> <ants> <start> <end>" lines
+	Avoid         []string      // rooms from "##avoid <room>..." lines: no path may enter them
+	Via           []string      // rooms from "##via <room>..." lines: some path must pass each
+	OriginalLines []string      // sanitized lines to echo before moves
+}
+
+// MainGroup is the colony given by the ants line and ##start/##end.
+const MainGroup = "main"
+
+// AntGroups lists every colony on the map: the main one first, then the
+// ##group lines in file order.
+func (r *Result) AntGroups() []model.Group {
+	main := model.Group{Name: MainGroup, Ants: r.Ants, Start: r.Graph.Start, End: r.Graph.End}
+	return append([]model.Group{main}, r.Groups...)
+}
+
+// groupLine is a ##group directive waiting for its rooms to be defined.
+type groupLine struct {
+	name, start, end string
+	ants             int
 }
 
 func ParseFile(path string) (*Result, error) {
@@ -38,12 +60,13 @@ func Parse(scanner *bufio.Scanner) (*Result, error) {
 	lines := []string{}
 	phase := "ants" // ants -> rooms -> links
 	var pendingCommand string
+	var groups []groupLine
 	lineNo := 0
 	for scanner.Scan() {
 		lineNo++
 		line := scanner.Text()
 		if line == "" {
-			return nil, fmt.Errorf("%w, empty line", errInvalid)
+			return nil, fmt.Errorf("%w, empty line", ErrInvalid)
 		}
 		if strings.HasPrefix(line, "#") {
 			if strings.HasPrefix(line, "##") { // command
@@ -51,6 +74,27 @@ func Parse(scanner *bufio.Scanner) (*Result, error) {
 				if cmd == "##start" || cmd == "##end" {
 					pendingCommand = cmd
 					lines = append(lines, line)
+				} else if strings.HasPrefix(cmd, "##group ") {
+					f := strings.Fields(cmd)
+					ants := 0
+					if len(f) == 5 {
+						ants, _ = strconv.Atoi(f[2])
+					}
+					if ants <= 0 {
+						return nil, fmt.Errorf("%w, bad group line (want ##group <name> <ants> <start> <end>)", ErrInvalid)
+					}
+					groups = append(groups, groupLine{name: f[1], ants: ants, start: f[3], end: f[4]})
+					lines = append(lines, line)
+				} else if f := strings.Fields(cmd); f[0] == "##avoid" || f[0] == "##via" {
+					if len(f) < 2 {
+						return nil, fmt.Errorf("%w, %s needs at least one room", ErrInvalid, f[0])
+					}
+					if f[0] == "##avoid" {
+						res.Avoid = append(res.Avoid, f[1:]...)
+					} else {
+						res.Via = append(res.Via, f[1:]...)
+					}
+					lines = append(lines, line)
 				} else {
 					// ignore unknown command (do not store?) spec says ignore; we will echo anyway
 					lines = append(lines, line)
@@ -62,7 +106,7 @@ func Parse(scanner *bufio.Scanner) (*Result, error) {
 		if phase == "ants" {
 			ants, err := strconv.Atoi(line)
 			if err != nil || ants <= 0 {
-				return nil, fmt.Errorf("%w", errInvalid)
+				return nil, fmt.Errorf("%w", ErrInvalid)
 			}
 			res.Ants = ants
 			lines = append(lines, line) // allow empty? treat as error for simplicity
@@ -75,17 +119,17 @@ func Parse(scanner *bufio.Scanner) (*Result, error) {
 			x, _ := strconv.Atoi(m[2])
 			y, _ := strconv.Atoi(m[3])
 			if _, exists := res.Graph.Rooms[name]; exists {
-				return nil, fmt.Errorf("%w, duplicate room", errInvalid)
+				return nil, fmt.Errorf("%w, duplicate room", ErrInvalid)
 			}
 			r := res.Graph.AddRoom(name, x, y)
 			if pendingCommand == "##start" {
 				if res.Graph.Start != nil {
-					return nil, fmt.Errorf("%w, multiple start", errInvalid)
+					return nil, fmt.Errorf("%w, multiple start", ErrInvalid)
 				}
 				res.Graph.Start = r
 			} else if pendingCommand == "##end" {
 				if res.Graph.End != nil {
-					return nil, fmt.Errorf("%w, multiple end", errInvalid)
+					return nil, fmt.Errorf("%w, multiple end", ErrInvalid)
 				}
 				res.Graph.End = r
 			}
@@ -95,27 +139,48 @@ func Parse(scanner *bufio.Scanner) (*Result, error) {
 		}
 		// link lines transition phase
 		if linkLineRe.MatchString(line) {
-			if res.Graph.Start == nil || res.Graph.End == nil {
-				return nil, fmt.Errorf("%w, missing start or end", errInvalid)
-			}
 			phase = "links"
 			m := linkLineRe.FindStringSubmatch(line)
 			if !res.Graph.AddLink(m[1], m[2]) {
-				return nil, fmt.Errorf("%w, invalid link", errInvalid)
+				return nil, fmt.Errorf("%w, invalid link", ErrInvalid)
 			}
 			lines = append(lines, line)
 			continue
 		}
 		if phase == "links" { // after first link every next must be link
-			return nil, fmt.Errorf("%w, expected link", errInvalid)
+			return nil, fmt.Errorf("%w, expected link", ErrInvalid)
 		}
-		return nil, fmt.Errorf("%w, unrecognized line", errInvalid)
+		return nil, fmt.Errorf("%w, unrecognized line", ErrInvalid)
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
-	if res.Ants == 0 || res.Graph.Start == nil || res.Graph.End == nil {
-		return nil, fmt.Errorf("%w, missing essential data", errInvalid)
+	if res.Ants == 0 {
+		return nil, fmt.Errorf("%w, missing essential data", ErrInvalid)
+	}
+	// graph-level rules, a missing start or end included, live in
+	// model.Graph.Validate (shared with library callers)
+	if issues := res.Graph.Validate(); len(issues) > 0 {
+		return nil, fmt.Errorf("%w, %s", ErrInvalid, issues[0].Message)
+	}
+	seen := map[string]bool{MainGroup: true}
+	for _, gl := range groups {
+		start, end := res.Graph.Rooms[gl.start], res.Graph.Rooms[gl.end]
+		switch {
+		case seen[gl.name]:
+			return nil, fmt.Errorf("%w, duplicate group %s", ErrInvalid, gl.name)
+		case start == nil || end == nil:
+			return nil, fmt.Errorf("%w, group %s uses an unknown room", ErrInvalid, gl.name)
+		case start == end:
+			return nil, fmt.Errorf("%w, group %s starts at its end", ErrInvalid, gl.name)
+		}
+		seen[gl.name] = true
+		res.Groups = append(res.Groups, model.Group{Name: gl.name, Ants: gl.ants, Start: start, End: end})
+	}
+	for _, name := range append(res.Avoid[:len(res.Avoid):len(res.Avoid)], res.Via...) {
+		if _, ok := res.Graph.Rooms[name]; !ok {
+			return nil, fmt.Errorf("%w, ##avoid or ##via names unknown room %s", ErrInvalid, name)
+		}
 	}
 	res.OriginalLines = lines
 	return res, nil
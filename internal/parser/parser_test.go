@@ -1 +1,90 @@
-package parser
\ No newline at end of file
+package parser
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	input := `3
+##group east 5 w e2
+##start
+s 0 0
+##end
+e 2 0
+w 0 1
+e2 2 1
+m 1 0
+s-m
+m-e
+w-m
+m-e2`
+	res, err := Parse(bufio.NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := res.AntGroups()
+	if len(groups) != 2 || groups[0].Name != MainGroup || groups[0].Ants != 3 {
+		t.Fatalf("groups %+v", groups)
+	}
+	if gr := groups[1]; gr.Name != "east" || gr.Ants != 5 || gr.Start.Name != "w" || gr.End.Name != "e2" {
+		t.Fatalf("group %+v", gr)
+	}
+
+	for _, bad := range []string{"##group east 0 w e2", "##group east 5 w nowhere", "##group east 5 w w", "##group east 5 w"} {
+		in := strings.Replace(input, "##group east 5 w e2", bad, 1)
+		if _, err := Parse(bufio.NewScanner(strings.NewReader(in))); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestParseAvoidAndVia(t *testing.T) {
+	input := `3
+##avoid b
+##start
+s 0 0
+##end
+e 2 0
+a 1 0
+b 1 1
+c 1 2
+##via a c
+s-a
+a-e
+s-b
+b-e
+s-c
+c-e`
+	res, err := Parse(bufio.NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.Avoid, " ") != "b" || strings.Join(res.Via, " ") != "a c" {
+		t.Fatalf("avoid %v, via %v", res.Avoid, res.Via)
+	}
+
+	for _, bad := range []string{"##avoid", "##avoid nowhere", "##via a nowhere"} {
+		in := strings.Replace(input, "##avoid b", bad, 1)
+		if _, err := Parse(bufio.NewScanner(strings.NewReader(in))); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+// A missing start or end is reported by model.Graph.Validate, with or
+// without links.
+func TestParseMissingStartOrEndUsesValidate(t *testing.T) {
+	for _, tc := range []struct{ input, want string }{
+		{"1\ns 0 0\n##end\ne 1 0\ns-e\n", "missing start room"},
+		{"1\n##start\ns 0 0\ne 1 0\ns-e\n", "missing end room"},
+		{"1\n##start\ns 0 0\ne 1 0\n", "missing end room"},
+		{"1\ns 0 0\ne 1 0\n", "missing start room"},
+	} {
+		_, err := Parse(bufio.NewScanner(strings.NewReader(tc.input)))
+		if err == nil || !strings.HasSuffix(err.Error(), ", "+tc.want) {
+			t.Errorf("%q: got %v, want an error ending in %q", tc.input, err, tc.want)
+		}
+	}
+}
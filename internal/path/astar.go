@@ -0,0 +1,203 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"lem-in/internal/model"
+)
+
+// Names of the A* path finders.
+const (
+	AlgoAStar         = "astar"
+	AlgoAStarWeighted = "astar-weighted"
+)
+
+/*
+AStar is MultiPath with the augmenting-path search guided by room coordinates,
+for very large grid-like maps where BFS floods the whole map for every unit of
+flow. Each augmentation is an A* search on the residual graph, ordered by cost
+so far plus the straight-line distance to End:
+
+  - unit links (default): every link costs 1, and the estimate is the
+    Euclidean distance to End divided by the longest link, so it never
+    overestimates the number of links still needed;
+  - Weighted: a link costs its Euclidean length and the estimate is the plain
+    distance to End.
+
+Room arcs cost 0, reverse arcs cost the same as their link (the heuristic is
+symmetric), so the estimate stays consistent on the residual graph. Any
+augmenting path raises the flow by one, so the flow value is the same as
+BFS; only the route of each augmentation and the work to find it change.
+Selection.Expanded reports the nodes the searches expanded.
+*/
+type AStar struct {
+	Weighted bool
+}
+
+func (a AStar) Name() string {
+	if a.Weighted {
+		return AlgoAStarWeighted
+	}
+	return AlgoAStar
+}
+
+func (AStar) breaksTies() {}
+
+func (a AStar) Settings() string {
+	if a.Weighted {
+		return "links=euclidean heuristic=euclidean"
+	}
+	return "links=unit heuristic=euclidean/longest-link"
+}
+
+func (a AStar) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	n, err := opts.Ties.network(g, opts.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	s := newAStarState(n, a.Weighted)
+	return selectBest(ctx, n, func(*network) int { return s.augment() }, opts.Ants, opts.MaxPaths), nil
+}
+
+// aStarState holds the per-arc costs and per-node estimates of one run, in
+// the network's buffers.
+type aStarState struct {
+	n    *network
+	cost []float64 // per arc
+	h    []float64 // per node: estimated cost to End
+}
+
+func newAStarState(n *network, weighted bool) *aStarState {
+	end := n.g.End
+	length := func(a, b *model.Room) float64 {
+		return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+	}
+	n.arcCost = resize(n.arcCost, len(n.edges))
+	n.h = resize(n.h, len(n.adj))
+	clear(n.arcCost)
+	clear(n.h)
+	s := &aStarState{n: n, cost: n.arcCost, h: n.h}
+
+	longest := 0.0
+	for ei := 0; ei < len(n.edges); ei += 2 {
+		from, to := n.edges[ei^1].to, n.edges[ei].to
+		if from/2 == to/2 {
+			continue // room arc: free
+		}
+		c := 1.0
+		if weighted {
+			c = length(n.g.Rooms[n.roomOf(from)], n.g.Rooms[n.roomOf(to)])
+		}
+		s.cost[ei], s.cost[ei^1] = c, c
+		longest = math.Max(longest, length(n.g.Rooms[n.roomOf(from)], n.g.Rooms[n.roomOf(to)]))
+	}
+
+	scale := 1.0
+	if !weighted {
+		scale = longest
+	}
+	for v := range s.h {
+		if scale > 0 {
+			s.h[v] = length(n.g.Rooms[n.roomOf(v)], end) / scale
+		}
+	}
+	return s
+}
+
+// augment pushes one unit along the cheapest residual path found by A*.
+// It returns the amount of flow pushed, 0 when none is left.
+func (s *aStarState) augment() int {
+	n := s.n
+	n.fdist = resize(n.fdist, len(n.adj))
+	n.via = resize(n.via, len(n.adj))
+	n.closed = resize(n.closed, len(n.adj))
+	dist, par, closed := n.fdist, n.via, n.closed // par: arc used to reach the node
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		par[i] = -1
+		closed[i] = false
+	}
+	dist[n.source] = 0
+	pq := &n.apq
+	*pq = append((*pq)[:0], aStarItem{node: n.source, f: s.h[n.source]})
+	for pq.Len() > 0 {
+		u := pq.pop().node
+		if closed[u] {
+			continue // stale entry
+		}
+		closed[u] = true
+		n.expanded++
+		if u == n.sink {
+			break
+		}
+		for _, ei := range n.adj[u] {
+			e := n.edges[ei]
+			if e.cap-e.flow <= 0 || closed[e.to] {
+				continue
+			}
+			if d := dist[u] + s.cost[ei]; d < dist[e.to] {
+				dist[e.to] = d
+				par[e.to] = ei
+				pq.push(aStarItem{node: e.to, f: d + s.h[e.to]})
+			}
+		}
+	}
+	if !closed[n.sink] {
+		return 0
+	}
+
+	bneck := inf
+	for v := n.sink; v != n.source; v = n.edges[par[v]^1].to {
+		e := n.edges[par[v]]
+		if e.cap-e.flow < bneck {
+			bneck = e.cap - e.flow
+		}
+	}
+	for v := n.sink; v != n.source; v = n.edges[par[v]^1].to {
+		n.push(par[v], bneck)
+	}
+	return bneck
+}
+
+type aStarItem struct {
+	node int
+	f    float64 // cost so far + estimate
+}
+
+// aStarHeap is a min-heap on f, ties broken by node id for determinism.
+type aStarHeap []aStarItem
+
+func (h aStarHeap) Len() int { return len(h) }
+func (h aStarHeap) Less(i, j int) bool {
+	if h[i].f != h[j].f {
+		return h[i].f < h[j].f
+	}
+	return h[i].node < h[j].node
+}
+func (h aStarHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *aStarHeap) Push(x interface{}) { *h = append(*h, x.(aStarItem)) }
+func (h *aStarHeap) Pop() interface{} {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// push and pop are heap.Push and heap.Pop without boxing (see nodeHeap).
+func (h *aStarHeap) push(x aStarItem) {
+	*h = append(*h, x)
+	heap.Fix(h, len(*h)-1)
+}
+
+func (h *aStarHeap) pop() aStarItem {
+	old := *h
+	it, last := old[0], len(old)-1
+	old[0] = old[last]
+	*h = old[:last]
+	if last > 0 {
+		heap.Fix(h, 0)
+	}
+	return it
+}
@@ -0,0 +1,87 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestAStarMatchesBFSFlow(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	for iter := 0; iter < 100; iter++ {
+		g := randomGraph(rng, 5+rng.Intn(25), 10+rng.Intn(60))
+		want := len(MultiPath(g, 0))
+		for _, a := range []AStar{{}, {Weighted: true}} {
+			sel, err := a.Find(context.Background(), g, Options{Ants: 1000})
+			got := 0
+			if err == nil && sel != nil {
+				got = maxCandidate(sel)
+			}
+			if got != want {
+				t.Fatalf("iter %d %s: flow %d, BFS %d", iter, a.Name(), got, want)
+			}
+		}
+	}
+}
+
+func TestAStarExpandsFewerNodesOnGrids(t *testing.T) {
+	g := openGrid(120, 120)
+	opts := Options{Ants: 1000}
+	bfs, err := Find(context.Background(), AlgoEdmondsKarp, g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{AlgoAStar, AlgoAStarWeighted} {
+		sel, err := Find(context.Background(), name, g, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if maxCandidate(sel) != maxCandidate(bfs) {
+			t.Fatalf("%s: flow %d, BFS %d", name, maxCandidate(sel), maxCandidate(bfs))
+		}
+		t.Logf("%s: %d nodes expanded, BFS %d", name, sel.Expanded, bfs.Expanded)
+		if sel.Expanded*4 > bfs.Expanded {
+			t.Errorf("%s expanded %d nodes, BFS %d: want at most a quarter", name, sel.Expanded, bfs.Expanded)
+		}
+	}
+}
+
+// openGrid is a w×h lattice with Start and End on the middle row, a quarter
+// of the width from either side.
+func openGrid(w, h int) *model.Graph {
+	g := model.NewGraph()
+	name := func(x, y int) string { return fmt.Sprintf("g%d_%d", x, y) }
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			g.AddRoom(name(x, y), x, y)
+			if x > 0 {
+				g.AddLink(name(x-1, y), name(x, y))
+			}
+			if y > 0 {
+				g.AddLink(name(x, y-1), name(x, y))
+			}
+		}
+	}
+	g.Start = g.Rooms[name(w/4, h/2)]
+	g.End = g.Rooms[name(w-1-w/4, h/2)]
+	return g
+}
+
+// BenchmarkAStar times whole Find runs against Edmonds–Karp on a large open
+// grid: fewer expanded nodes only pay off if the rest of the run stays cheap.
+func BenchmarkAStar(b *testing.B) {
+	g := openGrid(300, 300)
+	for _, algo := range []string{AlgoEdmondsKarp, AlgoAStar, AlgoAStarWeighted} {
+		b.Run(algo, func(b *testing.B) {
+			opts := Options{Ants: 1000, Workspace: NewWorkspace()}
+			Find(context.Background(), algo, g, opts) // grow the workspace
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Find(context.Background(), algo, g, opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package path
+
+import (
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// Bounds are lower bounds on the number of turns any schedule needs.
+type Bounds struct {
+	ShortestPath int // length of the shortest Start→End path
+	Cut          int // from the min cut and the path lengths through it
+	CutSize      int // rooms and tunnels in that cut (= max flow)
+}
+
+// Best is the strongest of the bounds.
+func (b *Bounds) Best() int {
+	if b.Cut > b.ShortestPath {
+		return b.Cut
+	}
+	return b.ShortestPath
+}
+
+// Gap is how many turns above the best bound a result is.
+func (b *Bounds) Gap(turns int) int { return turns - b.Best() }
+
+/*
+LowerBounds computes two lower bounds on the turns needed to move ants:
+
+  - the shortest path: the first ant cannot arrive sooner;
+  - the min cut: every ant crosses one of its rooms (or tunnels), and a room
+    takes one ant per turn. If k ants use cut room c, whose shortest
+    Start→c→End route has length l_c, the last of them enters c no earlier
+    than turn d(Start,c)+k-1 and arrives no earlier than l_c+k-1. Spreading
+    the ants over the cut as well as possible is the scheduler's own (L-1)
+    balancing with the lengths l_c, so that formula gives the bound.
+
+Both hold for every schedule, including ones that wait or share routes, so
+a turn count equal to Best is optimal. It returns nil when End is unreachable.
+*/
+func LowerBounds(g *model.Graph, ants int) *Bounds {
+	return LowerBoundsFrom(g, ants, MinCut(g))
+}
+
+// LowerBoundsFrom is LowerBounds for a caller that already has g's MinCut.
+func LowerBoundsFrom(g *model.Graph, ants int, cut *Cut) *Bounds {
+	if cut == nil || cut.Flow == 0 {
+		return nil
+	}
+	fromStart := distances(g, g.Start)
+	toEnd := distances(g, g.End)
+	b := &Bounds{ShortestPath: fromStart[g.End], CutSize: cut.Flow}
+	if ants <= 0 {
+		return b
+	}
+
+	var lens []*model.Path // only Length is used
+	for _, name := range cut.Rooms {
+		r := g.Rooms[name]
+		lens = append(lens, &model.Path{Length: fromStart[r] + toEnd[r]})
+	}
+	for _, l := range cut.Links {
+		u, v := g.Rooms[l[0]], g.Rooms[l[1]]
+		lens = append(lens, &model.Path{Length: fromStart[u] + 1 + toEnd[v]})
+	}
+	b.Cut = scheduler.TurnCount(ants, lens)
+	return b
+}
+
+// distances is the BFS link distance from r to every room reachable from it.
+func distances(g *model.Graph, r *model.Room) map[*model.Room]int {
+	dist := map[*model.Room]int{r: 0}
+	q := []*model.Room{r}
+	for len(q) > 0 {
+		u := q[0]
+		q = q[1:]
+		for _, v := range u.Links {
+			if _, ok := dist[v]; !ok {
+				dist[v] = dist[u] + 1
+				q = append(q, v)
+			}
+		}
+	}
+	return dist
+}
@@ -0,0 +1,52 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestLowerBoundsNeverExceedOptimum(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for iter := 0; iter < 80; iter++ {
+		g := randomGraph(rng, 5+rng.Intn(12), 8+rng.Intn(30))
+		ants := 1 + rng.Intn(25)
+		b := LowerBounds(g, ants)
+		exact, err := Find(context.Background(), AlgoExact, g, Options{Ants: ants})
+		if b == nil {
+			if err != ErrNoPath {
+				t.Fatalf("iter %d: no bounds but exact returned %v", iter, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Best() > exact.Turns || b.ShortestPath > b.Best() {
+			t.Fatalf("iter %d: bounds %+v, optimum %d", iter, b, exact.Turns)
+		}
+	}
+}
+
+// Three routes funnel through room h: the shortest path alone says 3 turns,
+// the cut says ants queue at h one per turn.
+func TestCutBoundCountsTheQueue(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "h"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"s", "a"}, {"s", "b"}, {"a", "h"}, {"b", "h"}, {"h", "e"}} {
+		g.AddLink(l[0], l[1])
+	}
+	b := LowerBounds(g, 10)
+	if b.ShortestPath != 3 || b.CutSize != 1 || b.Cut != 12 {
+		t.Fatalf("bounds %+v, want shortest 3, cut size 1, cut bound 12", b)
+	}
+	if sel := BestPaths(g, 10, 0); b.Gap(sel.Turns) != 0 {
+		t.Errorf("gap %d for %d turns, want 0", b.Gap(sel.Turns), sel.Turns)
+	}
+}
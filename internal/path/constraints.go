@@ -0,0 +1,226 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// ErrUnsatisfiable is returned by Find when no path set meets the Avoid and
+// Via options. The wrapping error says which constraint failed.
+var ErrUnsatisfiable = errors.New("path constraints cannot be satisfied")
+
+/*
+findConstrained runs f under opts.Avoid and opts.Via.
+
+Avoided rooms lose their capacity: no flow, and so no path, may pass them.
+Waypoints are routed one at a time, in the order given. For a waypoint w the
+route Start→w→End is two units of min-cost flow out of w into a sink joined
+to both Start and End with capacity one each, so one unit ends at Start and
+the other at End. Rooms on the route then lose their capacity as well, so the
+next waypoint's route (and the rest) stays room-disjoint from it. A waypoint
+already on an earlier route needs no route of its own.
+
+The remaining paths come from f on the map without the blocked rooms. The
+result is the waypoint routes plus as many of those, shortest first, as need
+the fewest turns, counting only sets in which the scheduler sends an ant
+along every waypoint route. Routing waypoints greedily may miss a combination that exists when
+several waypoints compete for the same rooms; the error then names the
+waypoint that could not be reached.
+*/
+func findConstrained(ctx context.Context, f PathFinder, g *model.Graph, opts Options) (*Selection, error) {
+	blocked := map[*model.Room]bool{}
+	for _, name := range opts.Avoid {
+		r, ok := g.Rooms[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("avoid: unknown room %q", name)
+		case r == g.Start || r == g.End:
+			return nil, fmt.Errorf("%w: every path uses %s, it cannot be avoided", ErrUnsatisfiable, name)
+		}
+		blocked[r] = true
+	}
+
+	var fixed []*model.Path
+	for _, name := range opts.Via {
+		w, ok := g.Rooms[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("via: unknown room %q", name)
+		case slices.Contains(opts.Avoid, name):
+			return nil, fmt.Errorf("%w: waypoint %s is also avoided", ErrUnsatisfiable, name)
+		case w == g.Start || w == g.End || onPaths(fixed, w):
+			continue
+		}
+		p := viaPath(g, w, blocked)
+		if p == nil {
+			return nil, fmt.Errorf("%w: no route from %s through %s to %s%s", ErrUnsatisfiable, g.Start.Name, name, g.End.Name, avoiding(blocked))
+		}
+		for _, r := range p.Rooms[1 : len(p.Rooms)-1] {
+			blocked[r] = true
+		}
+		fixed = append(fixed, p)
+	}
+	switch {
+	case opts.MaxPaths > 0 && len(fixed) > opts.MaxPaths:
+		return nil, fmt.Errorf("%w: %d waypoint routes exceed max-paths %d", ErrUnsatisfiable, len(fixed), opts.MaxPaths)
+	case len(fixed) > opts.Ants:
+		return nil, fmt.Errorf("%w: %d waypoint routes need at least as many ants, have %d", ErrUnsatisfiable, len(fixed), opts.Ants)
+	}
+
+	view := subgraph(g, func(r *model.Room) bool { return !blocked[r] })
+	view.Start, view.End = view.Rooms[g.Start.Name], view.Rooms[g.End.Name]
+	rest := opts
+	rest.Avoid, rest.Via = nil, nil
+	if rest.MaxPaths > 0 {
+		rest.MaxPaths -= len(fixed)
+	}
+
+	var sel *Selection
+	var err error
+	if opts.MaxPaths == 0 || rest.MaxPaths > 0 {
+		sel, err = f.Find(ctx, view, rest)
+	}
+	switch {
+	case err != nil && !errors.Is(err, ErrNoPath):
+		return nil, err
+	case len(fixed) == 0 && (sel == nil || len(sel.Paths) == 0):
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: no route from %s to %s%s", ErrUnsatisfiable, g.Start.Name, g.End.Name, avoiding(blocked))
+	case len(fixed) == 0:
+		sel.Paths = onto(g, sel.Paths)
+		return sel, nil // the finder's own schedule, if any, stays valid on g
+	}
+
+	var extra []*model.Path
+	var sc scorer
+	if sel != nil {
+		extra = sel.Paths
+		if sel.Scheduled != nil {
+			// a finder that schedules by itself may let paths share rooms,
+			// which the waypoint routes cannot be merged with
+			extra = MultiPath(view, rest.MaxPaths)
+		}
+		sc.partial = sel.Partial
+	}
+	extra = onto(g, extra)
+	sort.SliceStable(extra, func(i, j int) bool { return extra[i].Length < extra[j].Length })
+	for i := 0; i <= len(extra); i++ {
+		set := append(fixed[:len(fixed):len(fixed)], extra[:i]...)
+		if carries(set[:len(fixed)], scheduler.Assign(opts.Ants, set)) {
+			sc.consider(set, opts.Ants)
+		}
+	}
+	best := sc.result()
+	if best == nil {
+		return nil, fmt.Errorf("%w: the scheduler sends no ant along a waypoint route with %d ants", ErrUnsatisfiable, opts.Ants)
+	}
+	if sel != nil {
+		best.Expanded = sel.Expanded
+	}
+	return best, nil
+}
+
+// viaPath is the shortest Start→w→End route off the blocked rooms, or nil.
+func viaPath(g *model.Graph, w *model.Room, blocked map[*model.Room]bool) *model.Path {
+	n := newNetwork(g)
+	for r := range blocked {
+		n.block(r.Name)
+	}
+	// the two halves end at Start and End instead of passing them, and
+	// nothing flows back through w
+	for _, r := range []*model.Room{g.Start, g.End, w} {
+		n.block(r.Name)
+	}
+	sink := len(n.adj)
+	n.adj = append(n.adj, nil)
+	n.addEdge(n.in(g.Start.Name), sink, 1, 0)
+	n.addEdge(n.in(g.End.Name), sink, 1, 0)
+	n.source, n.sink = n.out(w.Name), sink
+	if n.augmentMinCost() == 0 || n.augmentMinCost() == 0 {
+		return nil
+	}
+
+	var toStart, toEnd []*model.Room
+	for i := 0; i < 2; i++ {
+		var half []*model.Room
+		for cur := n.consume(n.source); ; {
+			r := g.Rooms[n.roomOf(cur)] // cur is r_in
+			half = append(half, r)
+			if r == g.Start || r == g.End {
+				break
+			}
+			cur = n.consume(n.consume(cur)) // r_in -> r_out -> next room's in
+		}
+		if half[len(half)-1] == g.Start {
+			toStart = half
+		} else {
+			toEnd = half
+		}
+	}
+
+	rooms := make([]*model.Room, 0, len(toStart)+1+len(toEnd))
+	for i := len(toStart) - 1; i >= 0; i-- {
+		rooms = append(rooms, toStart[i])
+	}
+	rooms = append(rooms, w)
+	rooms = append(rooms, toEnd...)
+	return &model.Path{Rooms: rooms, Length: len(rooms) - 1}
+}
+
+// carries reports whether each of the leading paths gets an ant, given the
+// counts of scheduler.Assign.
+func carries(leading []*model.Path, counts []int) bool {
+	for i := range leading {
+		if counts[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// onPaths reports whether r lies on one of paths.
+func onPaths(paths []*model.Path, r *model.Room) bool {
+	for _, p := range paths {
+		for _, x := range p.Rooms {
+			if x == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// avoiding renders the blocked rooms for an error message, sorted.
+func avoiding(blocked map[*model.Room]bool) string {
+	if len(blocked) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(blocked))
+	for r := range blocked {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return " avoiding " + strings.Join(names, ", ")
+}
+
+// onto maps paths found on a copy of g (see subgraph) back onto g's rooms.
+func onto(g *model.Graph, paths []*model.Path) []*model.Path {
+	out := make([]*model.Path, len(paths))
+	for i, p := range paths {
+		rooms := make([]*model.Room, len(p.Rooms))
+		for j, r := range p.Rooms {
+			rooms[j] = g.Rooms[r.Name]
+		}
+		out[i] = &model.Path{Rooms: rooms, Length: p.Length}
+	}
+	return out
+}
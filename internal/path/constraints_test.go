@@ -0,0 +1,107 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+func TestConstraintsHoldOnRandomMaps(t *testing.T) {
+	rng := rand.New(rand.NewSource(45))
+	solved := 0
+	for iter := 0; iter < 300; iter++ {
+		rooms := 4 + rng.Intn(16)
+		g := randomGraph(rng, rooms, rooms+rng.Intn(3*rooms))
+		pick := func() string { return fmt.Sprintf("r%d", 1+rng.Intn(rooms-2)) }
+		opts := Options{Ants: 1 + rng.Intn(10), Avoid: []string{pick()}, Via: []string{pick()}}
+		if rng.Intn(2) == 0 {
+			opts.Via = append(opts.Via, pick())
+		}
+		algo := []string{AlgoEdmondsKarp, AlgoMinCost, AlgoGreedy, AlgoExact}[iter%4]
+
+		sel, err := Find(context.Background(), algo, g, opts)
+		if err != nil {
+			if !errors.Is(err, ErrUnsatisfiable) {
+				t.Fatalf("iter %d (%s, %v): %v", iter, algo, opts, err)
+			}
+			continue
+		}
+		solved++
+		used := map[*model.Room]bool{}   // rooms on some path
+		passed := map[*model.Room]bool{} // rooms some ant walks through
+		counts := scheduler.Assign(opts.Ants, sel.Paths)
+		for pi, p := range sel.Paths {
+			for i, r := range p.Rooms {
+				if i > 0 && !linked(p.Rooms[i-1], r) {
+					t.Fatalf("iter %d: %s-%s is not a tunnel", iter, p.Rooms[i-1].Name, r.Name)
+				}
+				if r == g.Start || r == g.End {
+					continue
+				}
+				if used[r] {
+					t.Fatalf("iter %d: room %s on two paths", iter, r.Name)
+				}
+				used[r] = true
+				passed[r] = counts[pi] > 0
+			}
+		}
+		if used[g.Rooms[opts.Avoid[0]]] {
+			t.Fatalf("iter %d (%s): avoided room %s used by %v", iter, algo, opts.Avoid[0], names(sel.Paths))
+		}
+		for _, w := range opts.Via {
+			if !passed[g.Rooms[w]] {
+				t.Fatalf("iter %d (%s): no ant passes waypoint %s on %v", iter, algo, w, names(sel.Paths))
+			}
+		}
+	}
+	if solved == 0 {
+		t.Fatal("no map was solvable")
+	}
+}
+
+// Waypoint d sits on the long side of the map: the route takes it even
+// though s-a-e is shorter, and a dead end cannot be a waypoint.
+func TestViaTakesDetourAndReportsDeadEnds(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "c", "d", "x"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"s", "a"}, {"a", "e"}, {"s", "b"}, {"b", "c"}, {"c", "d"}, {"d", "e"}, {"a", "x"}} {
+		g.AddLink(l[0], l[1])
+	}
+
+	sel, err := Find(context.Background(), AlgoEdmondsKarp, g, Options{Ants: 1, Via: []string{"d"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(names(sel.Paths)); got != "[[s b c d e]]" {
+		t.Errorf("paths %s, want [[s b c d e]]", got)
+	}
+	if !strings.Contains(sel.Settings, "via=d") {
+		t.Errorf("settings %q do not record the waypoint", sel.Settings)
+	}
+
+	for _, opts := range []Options{
+		{Ants: 1, Via: []string{"x"}},
+		{Ants: 1, Via: []string{"c"}, Avoid: []string{"b"}},
+		{Ants: 1, Via: []string{"a"}, Avoid: []string{"a"}},
+		{Ants: 1, Avoid: []string{"a", "b"}},
+		{Ants: 1, Avoid: []string{"s"}},
+	} {
+		_, err := Find(context.Background(), AlgoEdmondsKarp, g, opts)
+		if !errors.Is(err, ErrUnsatisfiable) {
+			t.Errorf("%v: err %v, want ErrUnsatisfiable", opts, err)
+		}
+	}
+	if _, err := Find(context.Background(), AlgoEdmondsKarp, g, Options{Ants: 1, Avoid: []string{"nope"}}); err == nil || errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("unknown room: err %v", err)
+	}
+}
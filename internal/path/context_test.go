@@ -0,0 +1,45 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countdownCtx reports Canceled once Err has been called more than n times.
+type countdownCtx struct {
+	context.Context
+	n int
+}
+
+func (c *countdownCtx) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestFindStopsWithPartialResult(t *testing.T) {
+	g := gridGraph(10, 6)
+	full, err := Find(context.Background(), AlgoEdmondsKarp, g, Options{Ants: 100})
+	if err != nil || full.Partial || full.Flow != 6 {
+		t.Fatalf("full run: %+v %v", full, err)
+	}
+
+	// allow two augmentations, then cancel
+	sel, err := Find(&countdownCtx{Context: context.Background(), n: 2}, AlgoEdmondsKarp, g, Options{Ants: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sel.Partial || len(sel.Candidates) != 2 {
+		t.Fatalf("want a partial result after 2 flow levels, got partial=%v candidates=%v", sel.Partial, sel.Candidates)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, name := range Names() {
+		if _, err := Find(ctx, name, g, Options{Ants: 100}); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: cancelled before any path, got err %v", name, err)
+		}
+	}
+}
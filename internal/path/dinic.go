@@ -0,0 +1,178 @@
+package path
+
+import (
+	"context"
+
+	"lem-in/internal/model"
+)
+
+/*
+Dinic (for large maps)
+----------------------
+Edmonds–Karp pays a full BFS, a fresh parent array and a list queue for every
+single unit of flow: O(F·(V+E)) with heavy allocation. Dinic instead builds a
+level graph once per phase (BFS distances from the source), then saturates it
+with a blocking flow using DFS and per-node edge iterators, so each phase
+pushes many paths at once. On unit-capacity networks like ours there are only
+O(√V) phases.
+
+Same split-node network as MultiPath, so the maximum flow — the number of
+room-disjoint paths — is identical; only the particular paths may differ.
+*/
+func Dinic(g *model.Graph, maxPaths int) []*model.Path {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	n := newNetwork(g)
+	d := newDinicState(n)
+	total := 0
+	for {
+		limit := inf
+		if maxPaths > 0 {
+			limit = maxPaths - total
+		}
+		pushed := d.phase(limit)
+		if pushed == 0 {
+			break
+		}
+		total += pushed
+		if maxPaths > 0 && total >= maxPaths {
+			break
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	return n.paths(maxPaths)
+}
+
+// BestDinic evaluates the path set after every Dinic phase and keeps the one
+// needing the fewest turns. Phases push several paths at once, so fewer flow
+// levels are compared than with BestPaths.
+func BestDinic(g *model.Graph, ants, maxPaths int) *Selection {
+	return BestDinicContext(context.Background(), g, ants, maxPaths)
+}
+
+// BestDinicContext is BestDinic that stops between phases when ctx is done.
+func BestDinicContext(ctx context.Context, g *model.Graph, ants, maxPaths int) *Selection {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	return bestDinic(ctx, newNetwork(g), ants, maxPaths)
+}
+
+// bestDinic runs the phases on n, scoring every flow level.
+func bestDinic(ctx context.Context, n *network, ants, maxPaths int) *Selection {
+	d := newDinicState(n)
+	var sc scorer
+	total := 0
+	for !sc.stopped(ctx) {
+		limit := inf
+		if maxPaths > 0 {
+			limit = maxPaths - total
+		}
+		pushed := d.phase(limit)
+		if pushed == 0 {
+			break
+		}
+		total += pushed
+		sc.considerFlow(n, ants, maxPaths)
+		if maxPaths > 0 && total >= maxPaths {
+			break
+		}
+	}
+	return sc.resultFor(n)
+}
+
+// dinicState holds the per-phase buffers, taken from the network's scratch
+// space so a Workspace reuses them.
+type dinicState struct {
+	n     *network
+	level []int
+	it    []int // next adj position to try, per node
+}
+
+func newDinicState(n *network) *dinicState {
+	n.level = resize(n.level, len(n.adj))
+	n.it = resize(n.it, len(n.adj))
+	return &dinicState{n: n, level: n.level, it: n.it}
+}
+
+// phase builds the level graph and pushes a blocking flow of at most limit.
+func (d *dinicState) phase(limit int) int {
+	if !d.buildLevels() {
+		return 0
+	}
+	for i := range d.it {
+		d.it[i] = 0
+	}
+	total := 0
+	for total < limit {
+		f := d.dfs(d.n.source, limit-total)
+		if f == 0 {
+			break
+		}
+		total += f
+	}
+	return total
+}
+
+// buildLevels runs a BFS from the source over residual edges and reports
+// whether the sink is reachable.
+func (d *dinicState) buildLevels() bool {
+	n := d.n
+	for i := range d.level {
+		d.level[i] = -1
+	}
+	d.level[n.source] = 0
+	q := append(n.queue[:0], n.source)
+	for head := 0; head < len(q); head++ {
+		u := q[head]
+		n.expanded++
+		for _, ei := range n.adj[u] {
+			e := &n.edges[ei]
+			if d.level[e.to] < 0 && e.cap-e.flow > 0 {
+				d.level[e.to] = d.level[u] + 1
+				q = append(q, e.to)
+			}
+		}
+	}
+	n.queue = q
+	return d.level[n.sink] >= 0
+}
+
+// dfs pushes up to f units from u to the sink along level-increasing edges.
+func (d *dinicState) dfs(u, f int) int {
+	n := d.n
+	if u == n.sink {
+		return f
+	}
+	for ; d.it[u] < len(n.adj[u]); d.it[u]++ {
+		ei := n.adj[u][d.it[u]]
+		e := &n.edges[ei]
+		if e.cap-e.flow <= 0 || d.level[e.to] != d.level[u]+1 {
+			continue
+		}
+		want := f
+		if r := e.cap - e.flow; r < want {
+			want = r
+		}
+		if got := d.dfs(e.to, want); got > 0 {
+			n.push(ei, got)
+			return got
+		}
+	}
+	return 0
+}
+
+type dinic struct{}
+
+func (dinic) Name() string { return AlgoDinic }
+func (dinic) breaksTies()  {}
+func (dinic) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	n, err := opts.Ties.network(g, opts.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	return bestDinic(ctx, n, opts.Ants, opts.MaxPaths), nil
+}
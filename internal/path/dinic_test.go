@@ -0,0 +1,79 @@
+package path
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"lem-in/internal/model"
+	"lem-in/internal/parser"
+)
+
+func TestDinicMatchesEdmondsKarp(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for iter := 0; iter < 200; iter++ {
+		g := randomGraph(rng, 5+rng.Intn(30), 10+rng.Intn(80))
+		if ek, dn := len(MultiPath(g, 0)), len(Dinic(g, 0)); ek != dn {
+			t.Fatalf("iter %d: Edmonds–Karp found %d paths, Dinic %d", iter, ek, dn)
+		}
+	}
+
+	files, _ := filepath.Glob("../../example*.txt")
+	for _, f := range files {
+		res, err := parser.ParseFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ek, dn := len(MultiPath(res.Graph, 0)), len(Dinic(res.Graph, 0)); ek != dn {
+			t.Errorf("%s: Edmonds–Karp found %d paths, Dinic %d", f, ek, dn)
+		}
+	}
+}
+
+// gridGraph builds a w×h grid; Start links to the whole left column and End
+// to the whole right column, so the max flow is h.
+func gridGraph(w, h int) *model.Graph {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("start", -1, 0)
+	g.End = g.AddRoom("end", w, 0)
+	name := func(x, y int) string { return fmt.Sprintf("r%d_%d", x, y) }
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			g.AddRoom(name(x, y), x, y)
+		}
+	}
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			if x+1 < w {
+				g.AddLink(name(x, y), name(x+1, y))
+			}
+			if y+1 < h {
+				g.AddLink(name(x, y), name(x, y+1))
+			}
+		}
+	}
+	for y := 0; y < h; y++ {
+		g.AddLink("start", name(0, y))
+		g.AddLink("end", name(w-1, y))
+	}
+	return g
+}
+
+func BenchmarkMaxFlow(b *testing.B) {
+	for _, size := range []struct{ w, h int }{{50, 20}, {250, 200}} {
+		g := gridGraph(size.w, size.h)
+		b.Run(fmt.Sprintf("edmonds-karp/%dx%d", size.w, size.h), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				MultiPath(g, 0)
+			}
+		})
+		b.Run(fmt.Sprintf("dinic/%dx%d", size.w, size.h), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Dinic(g, 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package path
+
+import (
+	"context"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+/*
+EdgeDisjoint (rule variant: rooms shared over time)
+---------------------------------------------------
+MultiPath forbids two paths from sharing a room, but the rules only forbid two
+ants being in one room in the *same* turn. Dropping the capacity-1 room edges
+(every room gets "infinite" capacity, links keep capacity 1) gives the maximum
+set of tunnel-disjoint paths. Such paths may cross, so they must be scheduled
+with scheduler.SimulateShared, which staggers ants through shared rooms.
+*/
+func EdgeDisjoint(g *model.Graph, maxPaths int) []*model.Path {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	n := buildNetwork(g, inf)
+	total := 0
+	for {
+		pushed := n.augment()
+		if pushed == 0 {
+			break
+		}
+		total += pushed
+		if maxPaths > 0 && total >= maxPaths {
+			break
+		}
+	}
+	return simplify(n.paths(maxPaths))
+}
+
+// BestEdgeDisjoint evaluates every flow level of EdgeDisjoint with the shared
+// room scheduler and keeps the path set that needs the fewest turns.
+func BestEdgeDisjoint(g *model.Graph, ants, maxPaths int) *Selection {
+	return BestEdgeDisjointContext(context.Background(), g, ants, maxPaths)
+}
+
+// BestEdgeDisjointContext is BestEdgeDisjoint that stops when ctx is done.
+func BestEdgeDisjointContext(ctx context.Context, g *model.Graph, ants, maxPaths int) *Selection {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	n := buildNetwork(g, inf)
+	var sc scorer
+	total := 0
+	for !sc.stopped(ctx) {
+		pushed := n.augment()
+		if pushed == 0 {
+			break
+		}
+		total += pushed
+		paths := simplify(n.paths(maxPaths))
+		sc.considerTurns(paths, scheduler.SimulateShared(ants, paths, g).Len())
+		if maxPaths > 0 && total >= maxPaths {
+			break
+		}
+	}
+	return sc.result()
+}
+
+// simplify cuts loops out of paths: once rooms may carry several units of
+// flow, the decomposition can walk around a cycle before reaching End.
+func simplify(paths []*model.Path) []*model.Path {
+	for _, p := range paths {
+		at := make(map[*model.Room]int, len(p.Rooms))
+		out := p.Rooms[:0:0]
+		for _, r := range p.Rooms {
+			if i, seen := at[r]; seen {
+				for _, dropped := range out[i+1:] {
+					delete(at, dropped)
+				}
+				out = out[:i+1]
+				continue
+			}
+			at[r] = len(out)
+			out = append(out, r)
+		}
+		p.Rooms = out
+		p.Length = len(out) - 1
+	}
+	return paths
+}
@@ -0,0 +1,234 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+/*
+Exact (minimum number of turns, small maps)
+-------------------------------------------
+The other finders pick disjoint paths and let the (L-1) scheduler fill them,
+which is a heuristic: ants may also wait in rooms or use paths that are not
+disjoint at all, as long as no room holds two ants in the same turn.
+
+The time-expanded network models every schedule of T turns exactly: one copy of
+each room per turn (split in/out, capacity 1 except Start and End), "wait" arcs
+from a room to its own copy one turn later, and "move" arcs along every tunnel
+from turn t to turn t+1 (capacity 1, like the path network). All ants start in
+Start at turn 0 and are absorbed by End. T turns suffice iff the max flow
+reaches `ants`; feasibility is monotone in T, so a binary search between the
+shortest-path length and the heuristic's turn count gives the true optimum, and
+decomposing that flow gives a schedule achieving it. If ctx ends during the
+search, the fastest feasible schedule so far (the heuristic's, until a
+shorter T was proven feasible) is returned with Partial set.
+*/
+type Exact struct {
+	MaxNodes int // refuse maps whose time-expanded network would exceed this
+}
+
+func (Exact) Name() string { return AlgoExact }
+
+func (e Exact) Settings() string { return fmt.Sprintf("max-nodes=%d", e.MaxNodes) }
+
+func (e Exact) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	if opts.Ants <= 0 {
+		return nil, nil
+	}
+	first := bfsPath(g, g.Start, nil, nil)
+	if first == nil {
+		return nil, nil
+	}
+	upper := BestPathsContext(ctx, g, opts.Ants, 0) // feasible, so an upper bound
+	if upper == nil || upper.Partial {
+		return upper, nil // ctx ended: the heuristic's best so far is all there is
+	}
+	lo, hi := first.Length, upper.Turns
+	if nodes := timeNodes(g, hi); e.MaxNodes > 0 && nodes > e.MaxNodes {
+		return nil, fmt.Errorf("%w: time-expanded network needs %d nodes for %d turns (limit %d)", ErrTooLarge, nodes, hi, e.MaxNodes)
+	}
+
+	// Invariant: hi turns are feasible; fewer than lo are not.
+	var best *timeNetwork
+	for lo < hi {
+		if ctx.Err() != nil {
+			// stopped early: return the fastest feasible schedule so far
+			sel := upper
+			if best != nil {
+				sel = best.selection()
+			}
+			sel.Partial = true
+			return sel, nil
+		}
+		mid := (lo + hi) / 2
+		if tn := solveTimeNetwork(g, opts.Ants, mid); tn.flow >= opts.Ants {
+			hi, best = mid, tn
+		} else {
+			lo = mid + 1
+		}
+	}
+	if best == nil || best.turns != hi {
+		best = solveTimeNetwork(g, opts.Ants, hi)
+	}
+	return best.selection(), nil
+}
+
+// timeNodes is the node count of the time-expanded network for T turns.
+func timeNodes(g *model.Graph, T int) int {
+	return 2*len(g.Rooms)*(T+1) + 2
+}
+
+// timeNetwork is a solved time-expanded network.
+type timeNetwork struct {
+	*network
+	turns int
+	flow  int
+	rooms int // rooms per layer
+}
+
+// node returns the in (or out) node of room id at turn t.
+func (tn *timeNetwork) node(id, t int, out bool) int {
+	n := 2 * (t*tn.rooms + id)
+	if out {
+		n++
+	}
+	return n
+}
+
+func solveTimeNetwork(g *model.Graph, ants, T int) *timeNetwork {
+	base := newNetwork(g) // reused for the room ids and names only
+	R := len(base.names)
+	n := &network{g: g, names: base.names, idOf: base.idOf}
+	n.adj = make([][]int, 2*R*(T+1)+2)
+	tn := &timeNetwork{network: n, turns: T, rooms: R}
+	superSource, sink := len(n.adj)-2, len(n.adj)-1
+	n.source, n.sink = superSource, sink
+
+	start, end := n.idOf[g.Start.Name], n.idOf[g.End.Name]
+	n.addEdge(superSource, tn.node(start, 0, false), ants, 0)
+	for t := 0; t <= T; t++ {
+		for id, nm := range n.names {
+			switch id {
+			case end:
+				n.addEdge(tn.node(id, t, false), sink, inf, 0) // absorbed
+				continue
+			case start:
+				n.addEdge(tn.node(id, t, false), tn.node(id, t, true), inf, 0)
+			default:
+				n.addEdge(tn.node(id, t, false), tn.node(id, t, true), 1, 0)
+			}
+			if t == T {
+				continue
+			}
+			waitCap := 1
+			if id == start {
+				waitCap = inf
+			}
+			n.addEdge(tn.node(id, t, true), tn.node(id, t+1, false), waitCap, 0)
+			nbs := make([]string, 0, len(g.Rooms[nm].Links))
+			for _, nb := range g.Rooms[nm].Links {
+				nbs = append(nbs, nb.Name)
+			}
+			sort.Strings(nbs)
+			for _, vn := range nbs {
+				if v := n.idOf[vn]; v != start { // ants never go back to Start
+					n.addEdge(tn.node(id, t, true), tn.node(v, t+1, false), 1, 1)
+				}
+			}
+		}
+	}
+
+	d := newDinicState(n)
+	for {
+		pushed := d.phase(inf)
+		if pushed == 0 {
+			break
+		}
+		tn.flow += pushed
+	}
+	return tn
+}
+
+// selection decomposes the flow into one trajectory per ant and schedules
+// the moves turn by turn. Ants are numbered by departure turn; each distinct
+// route becomes one of the paths, and Move.Path indexes it.
+func (tn *timeNetwork) selection() *Selection {
+	n := tn.network
+	start := n.idOf[n.g.Start.Name]
+	type trajectory struct {
+		rooms  []int // room id at turns 0..arrival
+		depart int
+	}
+	var trajs []trajectory
+	for {
+		cur := n.consume(n.source)
+		if cur == -1 {
+			break
+		}
+		tr := trajectory{depart: -1}
+		for cur != n.sink {
+			if cur%2 == 0 { // in node: the ant is in this room at this turn
+				id := (cur / 2) % tn.rooms
+				tr.rooms = append(tr.rooms, id)
+				if tr.depart == -1 && id != start {
+					tr.depart = len(tr.rooms) - 1
+				}
+			}
+			if cur = n.consume(cur); cur == -1 {
+				break
+			}
+		}
+		trajs = append(trajs, tr)
+	}
+	sort.SliceStable(trajs, func(i, j int) bool { return trajs[i].depart < trajs[j].depart })
+
+	sel := &Selection{}
+	turns := make([][]scheduler.Move, tn.turns)
+	index := map[string]int{} // route (room names, joined) -> index in sel.Paths
+	last := 0
+	for a, tr := range trajs {
+		route := []*model.Room{n.g.Start}
+		key := []string{n.g.Start.Name}
+		for t := 1; t < len(tr.rooms); t++ {
+			if tr.rooms[t] != tr.rooms[t-1] { // not waiting
+				route = append(route, n.g.Rooms[n.names[tr.rooms[t]]])
+				key = append(key, n.names[tr.rooms[t]])
+			}
+		}
+		k := strings.Join(key, "\x00")
+		pi, ok := index[k]
+		if !ok {
+			pi = len(sel.Paths)
+			index[k] = pi
+			sel.Paths = append(sel.Paths, &model.Path{Rooms: route, Length: len(route) - 1})
+		}
+		for t := 1; t < len(tr.rooms); t++ {
+			if tr.rooms[t] == tr.rooms[t-1] {
+				continue
+			}
+			turns[t-1] = append(turns[t-1], scheduler.Move{Ant: a + 1, From: n.names[tr.rooms[t-1]], To: n.names[tr.rooms[t]], Path: pi})
+			last = max(last, t)
+		}
+	}
+	sel.Scheduled = &scheduler.Schedule{Turns: turns[:last]}
+	sel.Turns = last
+	sel.Flow = len(sel.Paths)
+	return sel
+}
+
+// CheckOptimal compares the heuristic finder's turn count with the exact
+// optimum. It returns both selections.
+func CheckOptimal(ctx context.Context, algo string, g *model.Graph, opts Options) (heuristic, optimal *Selection, err error) {
+	if heuristic, err = Find(ctx, algo, g, opts); err != nil {
+		return nil, nil, err
+	}
+	if optimal, err = Find(ctx, AlgoExact, g, opts); err != nil {
+		return nil, nil, err
+	}
+	return heuristic, optimal, nil
+}
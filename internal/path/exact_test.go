@@ -0,0 +1,105 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// checkMoves replays sched and fails on a move along a missing tunnel or off
+// the ant's path, two ants in one intermediate room, an ant moving twice in a
+// turn, or an ant that never reaches End.
+func checkMoves(t *testing.T, g *model.Graph, ants int, sched *scheduler.Schedule, paths []*model.Path) {
+	t.Helper()
+	at := map[int]*model.Room{}
+	for id := 1; id <= ants; id++ {
+		at[id] = g.Start
+	}
+	for ti, turn := range sched.Turns {
+		moved := map[int]bool{}
+		for _, mv := range turn {
+			to := g.Rooms[mv.To]
+			linked := false
+			for _, l := range at[mv.Ant].Links {
+				linked = linked || l == to
+			}
+			if !linked || moved[mv.Ant] || mv.From != at[mv.Ant].Name {
+				t.Fatalf("turn %d: illegal move %+v", ti+1, mv)
+			}
+			if mv.Path < 0 || mv.Path >= len(paths) || !onPaths(paths[mv.Path:mv.Path+1], to) {
+				t.Fatalf("turn %d: move %+v leaves its path", ti+1, mv)
+			}
+			moved[mv.Ant] = true
+			at[mv.Ant] = to
+		}
+		held := map[*model.Room]int{}
+		for id, r := range at {
+			if r == g.Start || r == g.End {
+				continue
+			}
+			if other, ok := held[r]; ok {
+				t.Fatalf("turn %d: ants %d and %d both in %s", ti+1, other, id, r.Name)
+			}
+			held[r] = id
+		}
+	}
+	for id, r := range at {
+		if r != g.End {
+			t.Fatalf("ant %d ends in %s", id, r.Name)
+		}
+	}
+}
+
+func TestExactNeverWorseThanHeuristic(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for iter := 0; iter < 60; iter++ {
+		g := randomGraph(rng, 8, 14)
+		opts := Options{Ants: 1 + rng.Intn(10)}
+		heur, opt, err := CheckOptimal(context.Background(), AlgoEdmondsKarp, g, opts)
+		if err == ErrNoPath {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opt.Turns > heur.Turns {
+			t.Fatalf("iter %d: exact %d turns, heuristic %d", iter, opt.Turns, heur.Turns)
+		}
+		if opt.Scheduled.Len() != opt.Turns {
+			t.Fatalf("iter %d: %d scheduled turns for %d turns", iter, opt.Scheduled.Len(), opt.Turns)
+		}
+		checkMoves(t, g, opts.Ants, opt.Scheduled, opt.Paths)
+	}
+}
+
+func TestExactSizeGuard(t *testing.T) {
+	_, err := Exact{MaxNodes: 10}.Find(context.Background(), gridGraph(5, 5), Options{Ants: 10})
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("expected a size error, got %v", err)
+	}
+}
+
+// A context cancelled by the time the binary search starts still yields the
+// feasible upper bound, marked partial, instead of an error.
+func TestExactStopsWithBestSoFar(t *testing.T) {
+	g := gridGraph(4, 3)
+	const ants = 10
+	upper := BestPaths(g, ants, 0)
+	// selectBest checks ctx once per flow level and once more at the end
+	ctx := &countdownCtx{Context: context.Background(), n: len(upper.Candidates) + 1}
+	sel, err := Exact{}.Find(ctx, g, Options{Ants: ants})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sel.Partial || sel.Turns != upper.Turns || !reflect.DeepEqual(names(sel.Paths), names(upper.Paths)) {
+		t.Fatalf("got partial=%v %v in %d turns, want the heuristic's %v in %d", sel.Partial, names(sel.Paths), sel.Turns, names(upper.Paths), upper.Turns)
+	}
+	if sp := bfsPath(g, g.Start, nil, nil).Length; sp >= upper.Turns {
+		t.Fatalf("no search to cancel: shortest path %d, heuristic %d turns", sp, upper.Turns)
+	}
+}
@@ -0,0 +1,155 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// ErrTooLarge is returned by Exhaustive when the map exceeds its limits.
+var ErrTooLarge = errors.New("map too large for the exhaustive search")
+
+// errStopped unwinds the exhaustive search when the context is done.
+var errStopped = errors.New("search stopped")
+
+/*
+Exhaustive is the exact answer over room-disjoint path sets, for small maps:
+enumerate every simple Start→End path, then search all disjoint subsets for the
+one needing the fewest turns. Paths are tried shortest first, and a path of
+length L only receives ants when T > L-1, so the search stops extending a set as
+soon as the next path is at least as long as the best turn count.
+*/
+type Exhaustive struct {
+	MaxPaths int // give up when the map has more simple paths than this
+}
+
+func (Exhaustive) Name() string { return AlgoExhaustive }
+
+func (e Exhaustive) Settings() string { return fmt.Sprintf("simple-path-limit=%d", e.MaxPaths) }
+
+func (e Exhaustive) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	if ctx.Err() != nil {
+		return nil, nil
+	}
+	all, err := simplePaths(g, e.MaxPaths)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Length < all[j].Length })
+
+	bestByK := map[int]*Selection{}
+	var best *Selection
+	used := make(map[*model.Room]bool)
+	var chosen []*model.Path
+	steps := 0
+	const maxSteps = 2_000_000
+
+	var search func(from int, direct bool) error
+	search = func(from int, direct bool) error {
+		for i := from; i < len(all); i++ {
+			if opts.MaxPaths > 0 && len(chosen) >= opts.MaxPaths {
+				return nil
+			}
+			p := all[i]
+			if best != nil && p.Length-1 >= best.Turns {
+				return nil // this and every longer path would get no ants
+			}
+			inner := p.Rooms[1 : len(p.Rooms)-1]
+			if len(inner) == 0 && direct {
+				continue
+			}
+			free := true
+			for _, r := range inner {
+				free = free && !used[r]
+			}
+			if !free {
+				continue
+			}
+			if steps++; steps > maxSteps {
+				return fmt.Errorf("%w: more than %d path sets", ErrTooLarge, maxSteps)
+			}
+			if steps%1024 == 0 && ctx.Err() != nil {
+				return errStopped
+			}
+
+			for _, r := range inner {
+				used[r] = true
+			}
+			chosen = append(chosen, p)
+			turns := scheduler.TurnCount(opts.Ants, chosen)
+			k := len(chosen)
+			if b := bestByK[k]; b == nil || turns < b.Turns {
+				bestByK[k] = &Selection{Paths: append([]*model.Path(nil), chosen...), Flow: k, Turns: turns}
+			}
+			if best == nil || turns < best.Turns || (turns == best.Turns && k < best.Flow) {
+				best = bestByK[k]
+			}
+			err := search(i+1, direct || len(inner) == 0)
+			chosen = chosen[:len(chosen)-1]
+			for _, r := range inner {
+				used[r] = false
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	partial := false
+	if err := search(0, false); err == errStopped {
+		partial = true
+	} else if err != nil {
+		return nil, err
+	}
+	if best == nil {
+		return nil, nil
+	}
+	best.Partial = partial
+
+	for k := 1; bestByK[k] != nil; k++ {
+		best.Candidates = append(best.Candidates, Candidate{Flow: k, Turns: bestByK[k].Turns})
+	}
+	return best, nil
+}
+
+// simplePaths enumerates every simple Start→End path, neighbours by name.
+func simplePaths(g *model.Graph, limit int) ([]*model.Path, error) {
+	var all []*model.Path
+	onPath := map[*model.Room]bool{g.Start: true}
+	stack := []*model.Room{g.Start}
+	var walk func(u *model.Room) error
+	walk = func(u *model.Room) error {
+		nbs := append([]*model.Room(nil), u.Links...)
+		sort.Slice(nbs, func(i, j int) bool { return nbs[i].Name < nbs[j].Name })
+		for _, v := range nbs {
+			if onPath[v] {
+				continue
+			}
+			if v == g.End {
+				rooms := append(append([]*model.Room(nil), stack...), v)
+				all = append(all, &model.Path{Rooms: rooms, Length: len(rooms) - 1})
+				if limit > 0 && len(all) > limit {
+					return fmt.Errorf("%w: more than %d simple paths", ErrTooLarge, limit)
+				}
+				continue
+			}
+			onPath[v] = true
+			stack = append(stack, v)
+			err := walk(v)
+			stack = stack[:len(stack)-1]
+			onPath[v] = false
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return all, walk(g.Start)
+}
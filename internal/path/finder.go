@@ -0,0 +1,191 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"lem-in/internal/model"
+)
+
+// ErrNoPath is returned by path finders when End cannot be reached.
+var ErrNoPath = errors.New("no path found")
+
+// Names of the built-in path finders.
+const (
+	AlgoEdmondsKarp = "edmonds-karp"
+	AlgoMinCost     = "mincost"
+	AlgoGreedy      = "greedy"
+	AlgoExhaustive  = "exhaustive"
+	AlgoDinic       = "dinic"
+	AlgoExact       = "exact"
+)
+
+// Options configures a PathFinder run.
+type Options struct {
+	Ants     int      // ants to route; the path set is chosen for this count
+	MaxPaths int      // upper bound on the number of paths; 0 means no limit
+	Ties     TieBreak // room order for equally good choices (max-flow finders)
+	Avoid    []string // rooms no path may enter
+	Via      []string // waypoints: each must lie on at least one path
+
+	// Workspace, when set, lends its buffers to the flow-based finders so
+	// repeated solves do not allocate a new network (see Workspace). Find
+	// uses it from the calling goroutine only.
+	Workspace *Workspace
+}
+
+// String renders the options as "key=value" pairs.
+func (o Options) String() string { return o.settings(true) }
+
+// settings is String, leaving out the tie policy unless ties is set.
+func (o Options) settings(ties bool) string {
+	s := fmt.Sprintf("ants=%d max-paths=%d", o.Ants, o.MaxPaths)
+	if ties {
+		s += " " + o.Ties.String()
+	}
+	if len(o.Avoid) > 0 {
+		s += " avoid=" + strings.Join(o.Avoid, ",")
+	}
+	if len(o.Via) > 0 {
+		s += " via=" + strings.Join(o.Via, ",")
+	}
+	return s
+}
+
+// PathFinder picks a set of room-disjoint Start→End paths for a graph.
+// When ctx is done it returns the best set found so far with Partial set.
+type PathFinder interface {
+	Name() string
+	Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error)
+}
+
+// settinger is implemented by finders with settings beyond Options.
+type settinger interface {
+	Settings() string
+}
+
+// tieBreaker is implemented by the finders that number rooms by
+// Options.Ties. The others ignore the policy, so their Settings omit it.
+type tieBreaker interface {
+	breaksTies()
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]PathFinder{
+		AlgoEdmondsKarp:   edmondsKarp{},
+		AlgoMinCost:       minCost{},
+		AlgoGreedy:        greedy{},
+		AlgoExhaustive:    Exhaustive{MaxPaths: 5000},
+		AlgoDinic:         dinic{},
+		AlgoExact:         Exact{MaxNodes: 200_000},
+		AlgoPortfolio:     DefaultPortfolio(),
+		AlgoAStar:         AStar{},
+		AlgoAStarWeighted: AStar{Weighted: true},
+	}
+)
+
+// Register makes a PathFinder available to Lookup and Find under f.Name().
+// It panics if the name is already taken.
+func Register(f PathFinder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[f.Name()]; dup {
+		panic("path: Register called twice for " + f.Name())
+	}
+	registry[f.Name()] = f
+}
+
+// Lookup returns the PathFinder registered under name.
+// An empty name selects Edmonds–Karp.
+func Lookup(name string) (PathFinder, error) {
+	if name == "" {
+		name = AlgoEdmondsKarp
+	}
+	registryMu.RLock()
+	f, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown path algorithm %q (want one of %s)", name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Names lists the registered path finders, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find runs the named path finder and stamps the result with the finder's
+// name and settings. If ctx ends before any path was found, ctx.Err() is
+// returned. Avoid and Via constraints are applied around the finder (see
+// findConstrained); when they cannot be met the error wraps ErrUnsatisfiable.
+func Find(ctx context.Context, name string, g *model.Graph, opts Options) (*Selection, error) {
+	f, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil, ErrNoPath
+	}
+	if _, err := opts.Ties.order(g); err != nil {
+		return nil, err
+	}
+	var sel *Selection
+	if len(opts.Avoid) > 0 || len(opts.Via) > 0 {
+		sel, err = findConstrained(ctx, f, g, opts)
+	} else {
+		sel, err = f.Find(ctx, g, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sel == nil || len(sel.Paths) == 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrNoPath
+	}
+	sel.Algo = f.Name()
+	_, ties := f.(tieBreaker)
+	sel.Settings = opts.settings(ties)
+	if s, ok := f.(settinger); ok {
+		sel.Settings += " " + s.Settings()
+	}
+	return sel, nil
+}
+
+type edmondsKarp struct{}
+
+func (edmondsKarp) Name() string { return AlgoEdmondsKarp }
+func (edmondsKarp) breaksTies()  {}
+func (edmondsKarp) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	n, err := opts.Ties.network(g, opts.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	return selectBest(ctx, n, (*network).augment, opts.Ants, opts.MaxPaths), nil
+}
+
+type minCost struct{}
+
+func (minCost) Name() string { return AlgoMinCost }
+func (minCost) breaksTies()  {}
+func (minCost) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	n, err := opts.Ties.network(g, opts.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	return selectBest(ctx, n, (*network).augmentMinCost, opts.Ants, opts.MaxPaths), nil
+}
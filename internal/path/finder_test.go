@@ -0,0 +1,43 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestFindersNeverBeatExhaustive(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 40; iter++ {
+		g := randomGraph(rng, 9, 16)
+		opts := Options{Ants: 1 + rng.Intn(12)}
+		exact, err := Find(context.Background(), AlgoExhaustive, g, opts)
+		if err == ErrNoPath {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{AlgoEdmondsKarp, AlgoMinCost, AlgoGreedy} {
+			sel, err := Find(context.Background(), name, g, opts)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if sel.Algo != name || sel.Settings == "" {
+				t.Errorf("%s: result not stamped: %q %q", name, sel.Algo, sel.Settings)
+			}
+			if sel.Turns < exact.Turns {
+				t.Fatalf("iter %d: %s needs %d turns, below the exhaustive optimum %d", iter, name, sel.Turns, exact.Turns)
+			}
+		}
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("nope"); err == nil {
+		t.Fatal("expected an error for an unknown finder")
+	}
+	if f, err := Lookup(""); err != nil || f.Name() != AlgoEdmondsKarp {
+		t.Fatalf("empty name should select %s, got %v %v", AlgoEdmondsKarp, f, err)
+	}
+}
@@ -0,0 +1,90 @@
+package path
+
+import (
+	"context"
+	"sort"
+
+	"lem-in/internal/model"
+)
+
+/*
+Greedy is the baseline the max-flow finders are measured against:
+BFS the shortest Start→End path, remove its intermediate rooms, repeat.
+It never reroutes, so a path through a choke room can block others that a
+max-flow search would find. Kept for comparison, not for production runs.
+*/
+func Greedy(g *model.Graph, maxPaths int) []*model.Path {
+	var paths []*model.Path
+	greedyEach(g, maxPaths, func(p *model.Path) bool {
+		paths = append(paths, p)
+		return true
+	})
+	return paths
+}
+
+type greedy struct{}
+
+func (greedy) Name() string { return AlgoGreedy }
+func (greedy) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	var sc scorer
+	if sc.stopped(ctx) {
+		return nil, nil
+	}
+	var paths []*model.Path
+	greedyEach(g, opts.MaxPaths, func(p *model.Path) bool {
+		paths = append(paths, p)
+		sc.consider(append([]*model.Path(nil), paths...), opts.Ants)
+		return !sc.stopped(ctx)
+	})
+	return sc.result(), nil
+}
+
+// greedyEach calls emit for every path found, in order, until emit returns false.
+func greedyEach(g *model.Graph, maxPaths int, emit func(*model.Path) bool) {
+	used := make(map[*model.Room]bool)
+	direct := false // the Start—End link can carry one path only
+	for n := 0; maxPaths <= 0 || n < maxPaths; n++ {
+		p := shortestAvoiding(g, used, direct)
+		if p == nil {
+			return
+		}
+		for _, r := range p.Rooms[1 : len(p.Rooms)-1] {
+			used[r] = true
+		}
+		direct = direct || p.Length == 1
+		if !emit(p) {
+			return
+		}
+	}
+}
+
+// shortestAvoiding runs a BFS from Start to End that skips used rooms
+// (and the direct link when taken). Neighbours are visited by name.
+func shortestAvoiding(g *model.Graph, used map[*model.Room]bool, direct bool) *model.Path {
+	par := map[*model.Room]*model.Room{g.Start: nil}
+	q := []*model.Room{g.Start}
+	for len(q) > 0 {
+		u := q[0]
+		q = q[1:]
+		nbs := append([]*model.Room(nil), u.Links...)
+		sort.Slice(nbs, func(i, j int) bool { return nbs[i].Name < nbs[j].Name })
+		for _, v := range nbs {
+			if _, seen := par[v]; seen || used[v] {
+				continue
+			}
+			if v == g.End && u == g.Start && direct {
+				continue
+			}
+			par[v] = u
+			if v == g.End {
+				var rooms []*model.Room
+				for r := v; r != nil; r = par[r] {
+					rooms = append([]*model.Room{r}, rooms...)
+				}
+				return &model.Path{Rooms: rooms, Length: len(rooms) - 1}
+			}
+			q = append(q, v)
+		}
+	}
+	return nil
+}
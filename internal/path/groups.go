@@ -0,0 +1,188 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// GroupRoute is the path set chosen for one ant group.
+type GroupRoute struct {
+	Group model.Group
+	Paths []*model.Path
+}
+
+// GroupPlan is a joint solution for several ant groups.
+type GroupPlan struct {
+	Routes   []GroupRoute        // in the order of the groups given
+	Schedule *scheduler.Schedule // joint schedule, ants numbered group by group
+	Strategy string              // which variant of the joint search won
+}
+
+/*
+SolveGroups routes several ant groups (each with its own start and end) over
+one map and schedules them together: no intermediate room holds ants of two
+groups, or two ants of one group, at the end of the same turn.
+
+This is a multi-commodity flow problem, so instead of an LP it uses a small
+set of heuristics and keeps the one with the fewest turns:
+
+  - independent: every group gets the paths the named finder picks for it
+    alone; the joint scheduler resolves crossings by delaying ants;
+  - avoid: groups are routed one after another, and a group avoids the rooms
+    earlier groups' paths use when it can still reach its end without them.
+    Tried in map order and by descending ant count;
+  - congestion: groups routed one after another with min-cost flow, where
+    entering a room an earlier group uses costs congestionCost extra links,
+    so a group shares rooms only where a detour would be longer.
+
+A group's paths never pass through another group's start or end room.
+Ties go to the earlier variant.
+*/
+func SolveGroups(ctx context.Context, algo string, g *model.Graph, groups []model.Group) (*GroupPlan, error) {
+	terminals := map[*model.Room]bool{}
+	for _, gr := range groups {
+		terminals[gr.Start], terminals[gr.End] = true, true
+	}
+
+	byAnts := make([]int, len(groups))
+	for i := range byAnts {
+		byAnts[i] = i
+	}
+	sort.SliceStable(byAnts, func(a, b int) bool { return groups[byAnts[a]].Ants > groups[byAnts[b]].Ants })
+	inOrder := make([]int, len(groups))
+	for i := range inOrder {
+		inOrder[i] = i
+	}
+
+	const (
+		independent = iota
+		avoid
+		congestion
+	)
+	variants := []struct {
+		name  string
+		mode  int
+		order []int
+	}{
+		{"independent", independent, inOrder},
+		{"avoid (map order)", avoid, inOrder},
+		{"avoid (most ants first)", avoid, byAnts},
+		{"congestion (map order)", congestion, inOrder},
+		{"congestion (most ants first)", congestion, byAnts},
+	}
+	var best *GroupPlan
+	for _, v := range variants {
+		if err := ctx.Err(); err != nil {
+			if best != nil {
+				return best, nil
+			}
+			return nil, err
+		}
+		routes := make([]GroupRoute, len(groups))
+		used := map[*model.Room]bool{}
+		for _, gi := range v.order {
+			gr := groups[gi]
+			var paths []*model.Path
+			var err error
+			switch v.mode {
+			case avoid:
+				paths, err = groupPaths(ctx, algo, g, gr, terminals, used, nil)
+				if err != nil { // no way around: share
+					paths, err = groupPaths(ctx, algo, g, gr, terminals, nil, nil)
+				}
+			case congestion:
+				paths, err = groupPaths(ctx, algo, g, gr, terminals, nil, used)
+			default:
+				paths, err = groupPaths(ctx, algo, g, gr, terminals, nil, nil)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("group %s: %w", gr.Name, err)
+			}
+			for _, p := range paths {
+				for _, r := range p.Rooms[1 : len(p.Rooms)-1] {
+					used[r] = true
+				}
+			}
+			routes[gi] = GroupRoute{Group: gr, Paths: paths}
+		}
+
+		sched := make([]scheduler.GroupPaths, len(routes))
+		for i, r := range routes {
+			sched[i] = scheduler.GroupPaths{Ants: r.Group.Ants, Paths: r.Paths}
+		}
+		joint := scheduler.SimulateGroups(sched)
+		if best == nil || joint.Len() < best.Schedule.Len() {
+			best = &GroupPlan{Routes: routes, Schedule: joint, Strategy: v.name}
+		}
+	}
+	return best, nil
+}
+
+// congestionCost is the extra cost, in links, of entering a room another
+// group already uses.
+const congestionCost = 2
+
+// groupPaths runs the finder for one group on the map without the other
+// groups' terminals and the avoided rooms, and maps the paths back onto g.
+// With congested rooms given it runs min-cost flow with those rooms priced
+// up instead of the named finder.
+func groupPaths(ctx context.Context, algo string, g *model.Graph, gr model.Group, terminals, avoid, congested map[*model.Room]bool) ([]*model.Path, error) {
+	keep := func(r *model.Room) bool {
+		if r == gr.Start || r == gr.End {
+			return true
+		}
+		return !terminals[r] && !avoid[r]
+	}
+	view := subgraph(g, keep)
+	view.Start, view.End = view.Rooms[gr.Start.Name], view.Rooms[gr.End.Name]
+
+	var sel *Selection
+	var err error
+	if congested != nil {
+		n := newNetwork(view)
+		for r := range congested {
+			if id, ok := n.idOf[r.Name]; ok && r != gr.Start && r != gr.End {
+				ei := n.adj[2*id][0] // the room arc in -> out
+				n.edges[ei].cost, n.edges[ei^1].cost = congestionCost, -congestionCost
+			}
+		}
+		if sel = selectBest(ctx, n, (*network).augmentMinCost, gr.Ants, 0); sel == nil {
+			err = ErrNoPath
+		}
+	} else {
+		sel, err = Find(ctx, algo, view, Options{Ants: gr.Ants})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return onto(g, sel.Paths), nil
+}
+
+// subgraph copies the rooms of g that keep accepts, in insertion order, with
+// the links between them. Start and End are left unset.
+func subgraph(g *model.Graph, keep func(*model.Room) bool) *model.Graph {
+	rooms := make([]*model.Room, 0, len(g.Rooms))
+	for _, r := range g.Rooms {
+		if keep(r) {
+			rooms = append(rooms, r)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Order < rooms[j].Order })
+
+	sub := model.NewGraph()
+	for _, r := range rooms {
+		sub.AddRoom(r.Name, r.X, r.Y)
+	}
+	for _, r := range rooms {
+		for _, nb := range r.Links {
+			if _, ok := sub.Rooms[nb.Name]; ok {
+				sub.AddLink(r.Name, nb.Name)
+			}
+		}
+	}
+	return sub
+}
@@ -0,0 +1,98 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestSolveGroupsSchedulesValidMoves(t *testing.T) {
+	rng := rand.New(rand.NewSource(43))
+	solved := 0
+	for iter := 0; iter < 60; iter++ {
+		rooms := 10 + rng.Intn(15)
+		g := randomGraph(rng, rooms, 20+rng.Intn(40))
+		perm := rng.Perm(rooms)
+		var groups []model.Group
+		for k := 0; k < 2+rng.Intn(2); k++ {
+			groups = append(groups, model.Group{
+				Name:  fmt.Sprintf("g%d", k),
+				Ants:  1 + rng.Intn(8),
+				Start: g.Rooms[fmt.Sprintf("r%d", perm[2*k])],
+				End:   g.Rooms[fmt.Sprintf("r%d", perm[2*k+1])],
+			})
+		}
+		plan, err := SolveGroups(context.Background(), AlgoEdmondsKarp, g, groups)
+		if errors.Is(err, ErrNoPath) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		solved++
+		checkGroupMoves(t, g, groups, plan.Schedule.Lines())
+	}
+	if solved < 10 {
+		t.Fatalf("only %d of 60 maps had paths for every group", solved)
+	}
+}
+
+// checkGroupMoves replays moves: ants follow links, leave from their group's
+// start, end in its end, and never share an intermediate room or a tunnel
+// within a turn.
+func checkGroupMoves(t *testing.T, g *model.Graph, groups []model.Group, moves [][]string) {
+	t.Helper()
+	terminal := map[string]bool{}
+	var start, end []string
+	for _, gr := range groups {
+		terminal[gr.Start.Name], terminal[gr.End.Name] = true, true
+		for i := 0; i < gr.Ants; i++ {
+			start, end = append(start, gr.Start.Name), append(end, gr.End.Name)
+		}
+	}
+	where := append([]string(nil), start...)
+	for ti, turn := range moves {
+		crossed := map[[2]string]bool{}
+		for _, mv := range turn {
+			dash := strings.IndexByte(mv, '-')
+			id, _ := strconv.Atoi(mv[1:dash])
+			from, to := where[id-1], mv[dash+1:]
+			if !linked(g.Rooms[from], g.Rooms[to]) {
+				t.Fatalf("turn %d: ant %d moves %s -> %s without a tunnel", ti+1, id, from, to)
+			}
+			if to != end[id-1] && terminal[to] {
+				t.Fatalf("turn %d: ant %d passes through terminal %s", ti+1, id, to)
+			}
+			key := [2]string{from, to}
+			if from > to {
+				key = [2]string{to, from}
+			}
+			if crossed[key] {
+				t.Fatalf("turn %d: tunnel %v crossed twice", ti+1, key)
+			}
+			crossed[key] = true
+			where[id-1] = to
+		}
+		held := map[string]bool{}
+		for _, room := range where {
+			if terminal[room] {
+				continue
+			}
+			if held[room] {
+				t.Fatalf("turn %d: two ants in %s", ti+1, room)
+			}
+			held[room] = true
+		}
+	}
+	for i := range where {
+		if where[i] != end[i] {
+			t.Fatalf("ant %d ends in %s, want %s", i+1, where[i], end[i])
+		}
+	}
+}
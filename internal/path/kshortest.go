@@ -0,0 +1,159 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"lem-in/internal/model"
+)
+
+/*
+KShortest lists the k shortest simple Start→End paths (Yen's algorithm),
+shortest first, ties broken by room names. Unlike MultiPath the paths may
+share rooms: this is for analysis and teaching, not for routing ants.
+
+Yen's algorithm: after taking path A[k-1], every room on it becomes a "spur"
+room. The root (A[k-1] up to the spur) is kept, the links used by earlier
+paths with the same root are blocked, the root rooms are blocked, and a BFS
+from the spur room to End gives a new candidate. The cheapest candidate
+becomes A[k].
+*/
+func KShortest(g *model.Graph, k int) []*model.Path {
+	paths, _ := KShortestUntil(g, k, time.Time{})
+	return paths
+}
+
+// KShortestUntil is KShortest with a deadline (zero means none). It returns
+// the paths found so far and false when the deadline cut the search short.
+func KShortestUntil(g *model.Graph, k int, deadline time.Time) ([]*model.Path, bool) {
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return KShortestContext(ctx, g, k)
+}
+
+// KShortestContext is KShortest that stops when ctx is done. It returns the
+// paths found so far and false when ctx cut the search short.
+func KShortestContext(ctx context.Context, g *model.Graph, k int) ([]*model.Path, bool) {
+	if g == nil || g.Start == nil || g.End == nil || k <= 0 {
+		return nil, true
+	}
+	first := bfsPath(g, g.Start, nil, nil)
+	if first == nil {
+		return nil, true
+	}
+	A := []*model.Path{first}
+	seen := map[string]bool{pathKey(first): true}
+	B := &pathHeap{}
+
+	for len(A) < k {
+		prev := A[len(A)-1]
+		for i := 0; i < len(prev.Rooms)-1; i++ {
+			if ctx.Err() != nil {
+				return A, false
+			}
+			root := prev.Rooms[:i+1]
+			blockedLinks := map[[2]*model.Room]bool{}
+			for _, p := range A {
+				if len(p.Rooms) > i+1 && sameRooms(p.Rooms[:i+1], root) {
+					blockedLinks[[2]*model.Room{p.Rooms[i], p.Rooms[i+1]}] = true
+				}
+			}
+			blockedRooms := map[*model.Room]bool{}
+			for _, r := range root[:i] {
+				blockedRooms[r] = true
+			}
+			spur := bfsPath(g, prev.Rooms[i], blockedRooms, blockedLinks)
+			if spur == nil {
+				continue
+			}
+			rooms := append(append([]*model.Room(nil), root[:i]...), spur.Rooms...)
+			cand := &model.Path{Rooms: rooms, Length: len(rooms) - 1}
+			if key := pathKey(cand); !seen[key] {
+				seen[key] = true
+				heap.Push(B, cand)
+			}
+		}
+		if B.Len() == 0 {
+			break
+		}
+		A = append(A, heap.Pop(B).(*model.Path))
+	}
+	return A, true
+}
+
+// bfsPath returns a shortest path from `from` to End that avoids the blocked
+// rooms and links. Neighbours are visited by name for determinism.
+func bfsPath(g *model.Graph, from *model.Room, blockedRooms map[*model.Room]bool, blockedLinks map[[2]*model.Room]bool) *model.Path {
+	par := map[*model.Room]*model.Room{from: nil}
+	q := []*model.Room{from}
+	for len(q) > 0 {
+		u := q[0]
+		q = q[1:]
+		if u == g.End {
+			var rooms []*model.Room
+			for r := u; r != nil; r = par[r] {
+				rooms = append(rooms, r)
+			}
+			for i, j := 0, len(rooms)-1; i < j; i, j = i+1, j-1 {
+				rooms[i], rooms[j] = rooms[j], rooms[i]
+			}
+			return &model.Path{Rooms: rooms, Length: len(rooms) - 1}
+		}
+		nbs := append([]*model.Room(nil), u.Links...)
+		sort.Slice(nbs, func(i, j int) bool { return nbs[i].Name < nbs[j].Name })
+		for _, v := range nbs {
+			if _, ok := par[v]; ok || blockedRooms[v] || blockedLinks[[2]*model.Room{u, v}] {
+				continue
+			}
+			par[v] = u
+			q = append(q, v)
+		}
+	}
+	return nil
+}
+
+func sameRooms(a, b []*model.Room) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func pathKey(p *model.Path) string {
+	names := make([]string, len(p.Rooms))
+	for i, r := range p.Rooms {
+		names[i] = r.Name
+	}
+	return strings.Join(names, "\x00")
+}
+
+// pathHeap orders candidate paths by length, then by room names.
+type pathHeap []*model.Path
+
+func (h pathHeap) Len() int { return len(h) }
+func (h pathHeap) Less(i, j int) bool {
+	if h[i].Length != h[j].Length {
+		return h[i].Length < h[j].Length
+	}
+	return pathKey(h[i]) < pathKey(h[j])
+}
+func (h pathHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *pathHeap) Push(x interface{}) { *h = append(*h, x.(*model.Path)) }
+func (h *pathHeap) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
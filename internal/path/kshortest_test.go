@@ -0,0 +1,38 @@
+package path
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestKShortestMatchesEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for iter := 0; iter < 50; iter++ {
+		g := randomGraph(rng, 8, 14)
+		all, err := simplePaths(g, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.SliceStable(all, func(i, j int) bool { return all[i].Length < all[j].Length })
+
+		got := KShortest(g, 10)
+		want := len(all)
+		if want > 10 {
+			want = 10
+		}
+		if len(got) != want {
+			t.Fatalf("iter %d: got %d paths, want %d", iter, len(got), want)
+		}
+		seen := map[string]bool{}
+		for i, p := range got {
+			if p.Length != all[i].Length {
+				t.Fatalf("iter %d: path %d has length %d, want %d", iter, i, p.Length, all[i].Length)
+			}
+			if seen[pathKey(p)] {
+				t.Fatalf("iter %d: duplicate path %d", iter, i)
+			}
+			seen[pathKey(p)] = true
+		}
+	}
+}
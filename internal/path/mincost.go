@@ -0,0 +1,162 @@
+package path
+
+import (
+	"container/heap"
+	"context"
+
+	"lem-in/internal/model"
+)
+
+/*
+MinCost (Suurballe / Bhandari)
+------------------------------
+Edmonds–Karp finds *a* maximum set of room-disjoint paths, but nothing makes
+that set short: the BFS only minimises the length of each augmenting path, not
+the total length of the k paths it ends up with.
+
+MinCost runs successive-shortest-path min-cost flow on the same split-node
+network: room edges cost 0, link edges cost 1 (reverse arcs cost -1). Each step
+augments along the cheapest residual path, found with Dijkstra on reduced costs
+c(u,v) + pot[u] - pot[v], which stay non-negative because the potentials are
+the shortest distances of the previous round (Johnson's trick). After k steps
+the flow is a min-cost flow of value k, i.e. k disjoint paths of minimum total
+length — for every k, which is what the turn-based selection needs.
+*/
+func MinCost(g *model.Graph, maxPaths int) []*model.Path {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	n := newNetwork(g)
+	totalFlow := 0
+	for {
+		pushed := n.augmentMinCost()
+		if pushed == 0 {
+			break
+		}
+		totalFlow += pushed
+		if maxPaths > 0 && totalFlow >= maxPaths {
+			break
+		}
+	}
+	if totalFlow == 0 {
+		return nil
+	}
+	return n.paths(maxPaths)
+}
+
+// BestMinCost is BestPaths on top of MinCost's augmentations.
+func BestMinCost(g *model.Graph, ants, maxPaths int) *Selection {
+	return BestMinCostContext(context.Background(), g, ants, maxPaths)
+}
+
+// BestMinCostContext is BestMinCost that stops when ctx is done (see BestPathsContext).
+func BestMinCostContext(ctx context.Context, g *model.Graph, ants, maxPaths int) *Selection {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	return selectBest(ctx, newNetwork(g), (*network).augmentMinCost, ants, maxPaths)
+}
+
+// augmentMinCost pushes one unit along the cheapest residual path.
+// It returns the amount of flow pushed, 0 when Start and End are disconnected.
+func (n *network) augmentMinCost() int {
+	const unreached = int(^uint(0) >> 2)
+	if len(n.pot) == 0 {
+		n.pot = resize(n.pot, len(n.adj))
+		clear(n.pot) // all costs start non-negative
+	}
+	n.dist = resize(n.dist, len(n.adj))
+	n.via = resize(n.via, len(n.adj))
+	dist, par := n.dist, n.via // par: edge used to reach the node
+	for i := range dist {
+		dist[i] = unreached
+		par[i] = -1
+	}
+	dist[n.source] = 0
+	pq := &n.pq
+	*pq = append((*pq)[:0], nodeDist{node: n.source})
+	for pq.Len() > 0 {
+		it := pq.pop()
+		if it.dist > dist[it.node] {
+			continue // stale entry
+		}
+		u := it.node
+		n.expanded++
+		for _, ei := range n.adj[u] {
+			e := n.edges[ei]
+			if e.cap-e.flow <= 0 {
+				continue
+			}
+			nd := it.dist + e.cost + n.pot[u] - n.pot[e.to]
+			if nd < dist[e.to] {
+				dist[e.to] = nd
+				par[e.to] = ei
+				pq.push(nodeDist{node: e.to, dist: nd})
+			}
+		}
+	}
+	if dist[n.sink] == unreached {
+		return 0
+	}
+	// Nodes unreachable now stay unreachable (new reverse arcs only join
+	// reached nodes), so leaving their potential alone is safe.
+	for v, d := range dist {
+		if d != unreached {
+			n.pot[v] += d
+		}
+	}
+
+	bneck := inf
+	for v := n.sink; v != n.source; v = n.edges[par[v]^1].to {
+		e := n.edges[par[v]]
+		if e.cap-e.flow < bneck {
+			bneck = e.cap - e.flow
+		}
+	}
+	for v := n.sink; v != n.source; v = n.edges[par[v]^1].to {
+		n.push(par[v], bneck)
+	}
+	return bneck
+}
+
+type nodeDist struct {
+	node int
+	dist int
+}
+
+// nodeHeap is a min-heap on dist, ties broken by node id for determinism.
+type nodeHeap []nodeDist
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist < h[j].dist
+	}
+	return h[i].node < h[j].node
+}
+func (h nodeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(nodeDist)) }
+func (h *nodeHeap) Pop() interface{} {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// push and pop are heap.Push and heap.Pop without boxing the item in an
+// interface, so a reused queue does not allocate.
+func (h *nodeHeap) push(x nodeDist) {
+	*h = append(*h, x)
+	heap.Fix(h, len(*h)-1)
+}
+
+func (h *nodeHeap) pop() nodeDist {
+	old := *h
+	it, last := old[0], len(old)-1
+	old[0] = old[last]
+	*h = old[:last]
+	if last > 0 {
+		heap.Fix(h, 0)
+	}
+	return it
+}
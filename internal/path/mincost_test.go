@@ -0,0 +1,97 @@
+package path
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+// bruteMinTotal returns the minimum total length of k room-disjoint
+// Start→End paths by enumerating every simple path (small graphs only).
+func bruteMinTotal(g *model.Graph, k int) int {
+	var all [][]*model.Room
+	var walk func(r *model.Room, seen map[*model.Room]bool, acc []*model.Room)
+	walk = func(r *model.Room, seen map[*model.Room]bool, acc []*model.Room) {
+		if r == g.End {
+			all = append(all, append([]*model.Room(nil), acc...))
+			return
+		}
+		for _, nb := range r.Links {
+			if !seen[nb] {
+				seen[nb] = true
+				walk(nb, seen, append(acc, nb))
+				seen[nb] = false
+			}
+		}
+	}
+	walk(g.Start, map[*model.Room]bool{g.Start: true}, []*model.Room{g.Start})
+
+	best := -1
+	used := map[*model.Room]bool{}
+	var pick func(i, left, total int, direct bool)
+	pick = func(i, left, total int, direct bool) {
+		if left == 0 {
+			if best == -1 || total < best {
+				best = total
+			}
+			return
+		}
+		if i == len(all) {
+			return
+		}
+		p := all[i]
+		inner := p[1 : len(p)-1]
+		ok := !(len(inner) == 0 && direct)
+		for _, r := range inner {
+			ok = ok && !used[r]
+		}
+		if ok {
+			for _, r := range inner {
+				used[r] = true
+			}
+			pick(i+1, left-1, total+len(p)-1, direct || len(inner) == 0)
+			for _, r := range inner {
+				used[r] = false
+			}
+		}
+		pick(i+1, left, total, direct)
+	}
+	pick(0, k, 0, false)
+	return best
+}
+
+func randomGraph(rng *rand.Rand, rooms, links int) *model.Graph {
+	g := model.NewGraph()
+	for i := 0; i < rooms; i++ {
+		g.AddRoom(fmt.Sprintf("r%d", i), i, rng.Intn(rooms))
+	}
+	g.Start = g.Rooms["r0"]
+	g.End = g.Rooms[fmt.Sprintf("r%d", rooms-1)]
+	for i := 0; i < links; i++ {
+		g.AddLink(fmt.Sprintf("r%d", rng.Intn(rooms)), fmt.Sprintf("r%d", rng.Intn(rooms)))
+	}
+	return g
+}
+
+func TestMinCostIsMinimalForEveryK(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 60; iter++ {
+		g := randomGraph(rng, 9, 16)
+		max := len(MultiPath(g, 0))
+		for k := 1; k <= max; k++ {
+			paths := MinCost(g, k)
+			if len(paths) != k {
+				t.Fatalf("iter %d: MinCost(%d) returned %d paths", iter, k, len(paths))
+			}
+			total := 0
+			for _, p := range paths {
+				total += p.Length
+			}
+			if want := bruteMinTotal(g, k); total != want {
+				t.Fatalf("iter %d k=%d: total length %d, optimum %d", iter, k, total, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package path
+
+import (
+	"context"
+	"sort"
+
+	"lem-in/internal/model"
+)
+
+// Cut is a minimum Start/End cut of the room-disjoint flow network: the
+// rooms and tunnels that limit how many ants can travel in parallel.
+type Cut struct {
+	Flow  int         // max flow = number of room-disjoint paths = len(Rooms)+len(Links)
+	Rooms []string    // saturated room-capacity edges (v_in -> v_out), sorted
+	Links [][2]string // saturated tunnels u -> v crossing the cut, sorted
+}
+
+/*
+MinCut runs MultiPath's max flow and reads the minimum cut off the residual
+graph (max-flow/min-cut theorem): the nodes still reachable from Start_out
+form the source side, and every saturated edge leaving that side is a cut
+edge. A room edge v_in -> v_out in the cut means room v is a bottleneck; a
+link edge u_out -> v_in means the tunnel u—v is. A new tunnel only raises the
+flow if it bypasses one of them.
+*/
+func MinCut(g *model.Graph) *Cut {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	n := newNetwork(g)
+	flow, _ := n.maxFlow(context.Background(), 0)
+	return n.minCut(flow)
+}
+
+// minCut reads the cut off the current (maximum) flow.
+func (n *network) minCut(flow int) *Cut {
+	reach := make([]bool, len(n.adj))
+	reach[n.source] = true
+	q := []int{n.source}
+	for len(q) > 0 {
+		u := q[0]
+		q = q[1:]
+		for _, ei := range n.adj[u] {
+			e := n.edges[ei]
+			if !reach[e.to] && e.cap-e.flow > 0 {
+				reach[e.to] = true
+				q = append(q, e.to)
+			}
+		}
+	}
+
+	cut := &Cut{Flow: flow}
+	rooms := map[string]bool{}
+	for ei := 0; ei < len(n.edges); ei += 2 { // forward arcs only
+		e := n.edges[ei]
+		from := n.edges[ei^1].to
+		if !reach[from] || reach[e.to] || e.cap == 0 || e.flow < e.cap {
+			continue
+		}
+		to := n.roomOf(e.to)
+		switch {
+		case from/2 == e.to/2:
+			rooms[to] = true
+		case to != n.g.Start.Name && to != n.g.End.Name:
+			// Every path through tunnel u—v also passes room v (capacity 1),
+			// so naming the room gives a cut of the same size that is easier
+			// to act on.
+			rooms[to] = true
+		default:
+			cut.Links = append(cut.Links, [2]string{n.roomOf(from), to})
+		}
+	}
+	for r := range rooms {
+		cut.Rooms = append(cut.Rooms, r)
+	}
+	sort.Strings(cut.Rooms)
+	sort.Slice(cut.Links, func(i, j int) bool {
+		if cut.Links[i][0] != cut.Links[j][0] {
+			return cut.Links[i][0] < cut.Links[j][0]
+		}
+		return cut.Links[i][1] < cut.Links[j][1]
+	})
+	return cut
+}
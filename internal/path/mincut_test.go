@@ -0,0 +1,35 @@
+package path
+
+import (
+	"math/rand"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestMinCutSizeEqualsFlow(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for iter := 0; iter < 100; iter++ {
+		g := randomGraph(rng, 5+rng.Intn(20), 10+rng.Intn(50))
+		cut := MinCut(g)
+		if want := len(MultiPath(g, 0)); cut.Flow != want || len(cut.Rooms)+len(cut.Links) != want {
+			t.Fatalf("iter %d: flow %d, cut %v/%v, want size %d", iter, cut.Flow, cut.Rooms, cut.Links, want)
+		}
+	}
+}
+
+func TestMinCutFindsChokeRoom(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "h", "c", "d"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"s", "a"}, {"s", "b"}, {"a", "h"}, {"b", "h"}, {"h", "c"}, {"h", "d"}, {"c", "e"}, {"d", "e"}} {
+		g.AddLink(l[0], l[1])
+	}
+	cut := MinCut(g)
+	if len(cut.Rooms) != 1 || cut.Rooms[0] != "h" || len(cut.Links) != 0 {
+		t.Fatalf("cut = %+v, want room h only", cut)
+	}
+}
@@ -0,0 +1,308 @@
+package path
+
+import (
+	"cmp"
+	"slices"
+
+	"lem-in/internal/model"
+)
+
+// inf is the capacity of the Start and End room edges ("many paths may pass").
+const inf = 1_000_000
+
+// edge is one arc of the residual graph. Arcs are stored in pairs:
+// edges[i] is the forward arc and edges[i^1] its reverse.
+type edge struct {
+	to   int
+	cap  int
+	flow int
+	cost int // 1 per link, 0 for room edges; reverse arcs carry -cost
+}
+
+// network is the split-node flow network MultiPath works on:
+// room i becomes node 2i (in) and 2i+1 (out).
+type network struct {
+	g        *model.Graph
+	names    []string       // room names, index = room id
+	idOf     map[string]int // room name -> id
+	edges    []edge
+	adj      [][]int // node -> indexes into edges, in insertion order
+	source   int     // Start_out
+	sink     int     // End_in
+	pot      []int   // node potentials for min-cost augmentation
+	trace    *Trace  // optional recorder of BFS augmentations
+	expanded int     // nodes expanded by augmenting-path searches so far
+
+	// scratch space, kept between calls so a Workspace can reuse it
+	par     []parentInfo // BFS parents
+	queue   []int        // BFS queue (Dinic levels too)
+	walk    []int        // arcs of the paths found by decompose
+	lens    []int        // their lengths
+	kept    []int        // walk of the best flow level so far (selectBest)
+	keptLen []int        // its lengths
+	sorted  []int        // lengths being scored
+	nbs     []string     // neighbour names while building
+	dist    []int        // min-cost distances
+	via     []int        // arc used to reach each node (min cost, A*)
+	pq      nodeHeap     // min-cost queue
+	level   []int        // Dinic levels
+	it      []int        // Dinic edge iterators
+	fdist   []float64    // A* distances
+	closed  []bool       // A* closed set
+	apq     aStarHeap    // A* queue
+	arcCost []float64    // A* cost per arc
+	h       []float64    // A* estimate per node
+}
+
+// parentInfo records how augment reached a node.
+type parentInfo struct {
+	u  int // parent node
+	ei int // edge index
+}
+
+func newNetwork(g *model.Graph) *network {
+	return buildNetwork(g, 1)
+}
+
+// buildNetwork builds the split-node network with the given capacity on
+// intermediate room edges: 1 for room-disjoint paths, inf for edge-disjoint.
+func buildNetwork(g *model.Graph, roomCap int) *network {
+	// ---- Stable name ordering for deterministic results ----
+	names, _ := TieBreak{}.order(g)
+	return buildOrdered(g, roomCap, names)
+}
+
+// buildOrdered is buildNetwork with the room ids given by names: ties
+// between rooms go to the one listed first.
+func buildOrdered(g *model.Graph, roomCap int, names []string) *network {
+	n := &network{}
+	n.init(g, roomCap, names)
+	return n
+}
+
+// init (re)builds n in place for g, reusing the slices and map it already has.
+func (n *network) init(g *model.Graph, roomCap int, names []string) {
+	n.g, n.names = g, names
+	if n.idOf == nil {
+		n.idOf = make(map[string]int, len(names))
+	} else {
+		clear(n.idOf)
+	}
+	for i, nm := range names {
+		n.idOf[nm] = i
+	}
+	nodes := 2 * len(names)
+	if cap(n.adj) < nodes {
+		n.adj = append(n.adj[:cap(n.adj)], make([][]int, nodes-cap(n.adj))...)
+	}
+	n.adj = n.adj[:nodes]
+	for i := range n.adj {
+		n.adj[i] = n.adj[i][:0]
+	}
+	n.edges = n.edges[:0]
+	n.pot, n.trace, n.expanded = n.pot[:0], nil, 0
+	n.source = n.out(g.Start.Name)
+	n.sink = n.in(g.End.Name)
+
+	// Room edges v_in -> v_out: capacity roomCap, "infinite" for Start and End.
+	for _, nm := range names {
+		capacity := roomCap
+		if nm == g.Start.Name || nm == g.End.Name {
+			capacity = inf
+		}
+		n.addEdge(n.in(nm), n.out(nm), capacity, 0)
+	}
+
+	// Link edges u_out -> v_in with capacity ONE (see MultiPath for why),
+	// neighbours sorted by id to keep construction deterministic.
+	for _, nm := range names {
+		u := g.Rooms[nm]
+		nbs := n.nbs[:0]
+		for _, nb := range u.Links {
+			nbs = append(nbs, nb.Name)
+		}
+		slices.SortFunc(nbs, func(a, b string) int { return cmp.Compare(n.idOf[a], n.idOf[b]) })
+		for _, vn := range nbs {
+			n.addEdge(n.out(nm), n.in(vn), 1, 1)
+		}
+		n.nbs = nbs
+	}
+}
+
+// resize returns s with length size, reusing its array when it is big enough.
+// The contents are left as they were; callers initialise what they read.
+func resize[T any](s []T, size int) []T {
+	if cap(s) < size {
+		return make([]T, size)
+	}
+	return s[:size]
+}
+
+func (n *network) in(name string) int  { return 2 * n.idOf[name] }
+func (n *network) out(name string) int { return 2*n.idOf[name] + 1 }
+
+// roomOf maps a split node (in or out) back to its room name.
+func (n *network) roomOf(node int) string { return n.names[node/2] }
+
+func (n *network) addEdge(u, v, c, cost int) {
+	n.adj[u] = append(n.adj[u], len(n.edges))
+	n.edges = append(n.edges, edge{to: v, cap: c, cost: cost})
+	n.adj[v] = append(n.adj[v], len(n.edges))
+	n.edges = append(n.edges, edge{to: u, cap: 0, cost: -cost})
+}
+
+// block takes all capacity off a room's arc, so no flow passes the room.
+func (n *network) block(name string) {
+	if id, ok := n.idOf[name]; ok {
+		n.edges[n.adj[2*id][0]].cap = 0 // the room arc in -> out
+	}
+}
+
+// push sends f units along edge ei and updates its reverse.
+func (n *network) push(ei, f int) {
+	n.edges[ei].flow += f
+	n.edges[ei^1].flow -= f
+}
+
+// augment finds one shortest augmenting path with BFS (Edmonds–Karp step)
+// and applies it. It returns the amount of flow pushed, 0 when none is left.
+func (n *network) augment() int {
+	if cap(n.par) < len(n.adj) {
+		n.par = make([]parentInfo, len(n.adj))
+	}
+	par := n.par[:len(n.adj)]
+	for i := range par {
+		par[i] = parentInfo{-1, -1}
+	}
+	q := append(n.queue[:0], n.source)
+	par[n.source] = parentInfo{n.source, -1}
+
+	for head := 0; head < len(q); head++ {
+		u := q[head]
+		n.expanded++
+		if u == n.sink {
+			break
+		}
+		for _, ei := range n.adj[u] {
+			e := n.edges[ei]
+			if par[e.to].u == -1 && e.cap-e.flow > 0 {
+				par[e.to] = parentInfo{u, ei}
+				q = append(q, e.to)
+			}
+		}
+	}
+	n.queue = q
+	if par[n.sink].u == -1 {
+		return 0
+	}
+
+	// Compute bottleneck (here it's 1, but we do it properly).
+	bneck := inf
+	for v := n.sink; v != n.source; v = par[v].u {
+		e := n.edges[par[v].ei]
+		if e.cap-e.flow < bneck {
+			bneck = e.cap - e.flow
+		}
+	}
+	if bneck <= 0 {
+		return 0
+	}
+	if n.trace != nil {
+		var arcs []int
+		for v := n.sink; v != n.source; v = par[v].u {
+			arcs = append([]int{par[v].ei}, arcs...)
+		}
+		n.record(arcs, bneck)
+	}
+	for v := n.sink; v != n.source; v = par[v].u {
+		n.push(par[v].ei, bneck)
+	}
+	return bneck
+}
+
+// consume picks the flow-carrying edge out of u whose destination room sorts
+// first (ties by edge order) and takes one unit off it. It returns the
+// destination node, or -1 when no flow leaves u.
+func (n *network) consume(u int) int {
+	ei := n.consumeArc(u)
+	if ei == -1 {
+		return -1
+	}
+	return n.edges[ei].to
+}
+
+// consumeArc is consume returning the edge taken instead of its destination.
+func (n *network) consumeArc(u int) int {
+	best := -1
+	for _, ei := range n.adj[u] {
+		e := n.edges[ei]
+		if e.flow <= 0 {
+			continue
+		}
+		if best == -1 || e.to/2 < n.edges[best].to/2 {
+			best = ei
+		}
+	}
+	if best != -1 {
+		n.push(best, -1)
+	}
+	return best
+}
+
+// paths decomposes the current flow into room-disjoint Start→End paths.
+// The flow itself is left untouched, so augmenting may continue afterwards.
+func (n *network) paths(maxPaths int) []*model.Path {
+	n.decompose(maxPaths)
+	return n.pathsOf(n.walk, n.lens)
+}
+
+// decompose splits the current flow into Start→End paths like paths, without
+// building them: the arcs of every path go to n.walk, in order, and the path
+// lengths to n.lens. Both are reused by the next call. Only the arcs walked
+// are touched, and they are given their flow back, so the cost is the total
+// length of the paths rather than the size of the network.
+func (n *network) decompose(maxPaths int) {
+	walk, lens := n.walk[:0], n.lens[:0]
+	for maxPaths <= 0 || len(lens) < maxPaths {
+		// If no positive flow leaves the source anymore, we found all paths.
+		ei := n.consumeArc(n.source)
+		if ei == -1 {
+			break
+		}
+		walk = append(walk, ei)
+		links := 1
+		for cur := n.edges[ei].to; cur != n.sink; cur = n.edges[ei].to {
+			// v_in -> v_out (room capacity edge), then v_out -> w_in (link)
+			links++
+			if ei = n.consumeArc(cur); ei == -1 {
+				break // should not happen in a consistent flow
+			}
+			walk = append(walk, ei)
+			if ei = n.consumeArc(n.edges[ei].to); ei == -1 {
+				break
+			}
+			walk = append(walk, ei)
+		}
+		lens = append(lens, links)
+	}
+	for _, ei := range walk {
+		n.push(ei, 1)
+	}
+	n.walk, n.lens = walk, lens
+}
+
+// pathsOf builds the paths decompose recorded: a path of length l is 2l-1
+// arcs, the link into each room alternating with the room's own arc.
+func (n *network) pathsOf(walk, lens []int) []*model.Path {
+	paths := make([]*model.Path, 0, len(lens))
+	for _, l := range lens {
+		rooms := make([]*model.Room, l+1)
+		rooms[0], rooms[l] = n.g.Start, n.g.End
+		for j := 1; j < l; j++ {
+			rooms[j] = n.g.Rooms[n.roomOf(n.edges[walk[2*(j-1)]].to)]
+		}
+		walk = walk[min(2*l-1, len(walk)):]
+		paths = append(paths, &model.Path{Rooms: rooms, Length: l})
+	}
+	return paths
+}
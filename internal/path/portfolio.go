@@ -0,0 +1,175 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"lem-in/internal/model"
+)
+
+// AlgoPortfolio is the name of the portfolio path finder.
+const AlgoPortfolio = "portfolio"
+
+// Config is one path-finder configuration of a Portfolio. Every run is
+// scheduled the same way (see Portfolio).
+type Config struct {
+	Algo string
+	Ties TieBreak
+}
+
+// String renders the configuration as "<algo> ties=<policy>".
+func (c Config) String() string { return c.Algo + " " + c.Ties.String() }
+
+// PortfolioRun is how one configuration of a portfolio ended.
+type PortfolioRun struct {
+	Config    Config
+	Turns     int           // turns of its path set (0 if none)
+	Elapsed   time.Duration // wall time until it returned
+	Optimal   bool          // its turn count is provably the minimum
+	Cancelled bool          // stopped early: another run was optimal or ctx ended
+	Err       error
+}
+
+// PortfolioReport tells which configuration won and how each one ran.
+type PortfolioReport struct {
+	Winner     Config
+	LowerBound int // no schedule needs fewer turns
+	Runs       []PortfolioRun
+}
+
+/*
+Portfolio runs several path-finder configurations concurrently, one goroutine
+each, on the same map. Different maps favour different strategies and
+tie-break orders, and which one wins is cheap to find out by trying.
+
+A run is provably optimal when it comes from the exact solver, or when its
+turn count meets the best of LowerBounds. The first such run cancels the
+others. Otherwise all runs finish and the fewest turns
+win; ties go to a complete result over a partial one, then to the earlier
+configuration.
+
+Configurations differ in path finding only; scheduling is not a dimension.
+On room-disjoint paths the (L-1) assignment is already the fastest way to
+send the ants along a given path set (no ant ever waits, and the turn count
+is the formula's minimum), so another scheduler could only tie it. The
+schedulers that can do better change the paths as well: the exact solver,
+which lets ants wait and is in the default set, and the edge-disjoint mode,
+which plays by different rules.
+*/
+type Portfolio struct {
+	Configs []Config
+}
+
+// DefaultPortfolio is the configuration set behind the "portfolio" finder.
+func DefaultPortfolio() Portfolio {
+	return Portfolio{Configs: []Config{
+		{Algo: AlgoEdmondsKarp},
+		{Algo: AlgoEdmondsKarp, Ties: TieBreak{Policy: TieDistance}},
+		{Algo: AlgoEdmondsKarp, Ties: TieBreak{Policy: TieInsertion}},
+		{Algo: AlgoEdmondsKarp, Ties: TieBreak{Policy: TieRandom, Seed: 1}},
+		{Algo: AlgoMinCost},
+		{Algo: AlgoMinCost, Ties: TieBreak{Policy: TieDistance}},
+		{Algo: AlgoDinic},
+		{Algo: AlgoExhaustive},
+		{Algo: AlgoExact},
+	}}
+}
+
+func (Portfolio) Name() string { return AlgoPortfolio }
+
+func (p Portfolio) Settings() string {
+	parts := make([]string, len(p.Configs))
+	for i, c := range p.Configs {
+		parts[i] = c.Algo + "/" + c.Ties.policy()
+	}
+	return "configs=" + strings.Join(parts, ",")
+}
+
+// Find runs the portfolio and returns the winning selection with the report
+// attached (Selection.Portfolio). opts.Ties is ignored: each configuration
+// brings its own. So is opts.Workspace, as the configurations run at once.
+func (p Portfolio) Find(ctx context.Context, g *model.Graph, opts Options) (*Selection, error) {
+	for _, c := range p.Configs {
+		if c.Algo == AlgoPortfolio {
+			return nil, fmt.Errorf("portfolio cannot contain itself")
+		}
+		if _, err := Lookup(c.Algo); err != nil {
+			return nil, err
+		}
+	}
+	report := &PortfolioReport{}
+	if b := LowerBounds(g, opts.Ants); b != nil {
+		report.LowerBound = b.Best()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i   int
+		sel *Selection
+		run PortfolioRun
+	}
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i, c := range p.Configs {
+		wg.Add(1)
+		go func(i int, c Config) {
+			defer wg.Done()
+			o := opts
+			o.Ties = c.Ties
+			o.Workspace = nil // the runs are concurrent
+			begin := time.Now()
+			sel, err := Find(ctx, c.Algo, g, o)
+			run := PortfolioRun{Config: c, Elapsed: time.Since(begin), Err: err}
+			if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				run.Cancelled, run.Err = true, nil
+			}
+			if sel != nil {
+				run.Turns = sel.Turns
+				run.Cancelled = sel.Partial
+				run.Optimal = !sel.Partial && (c.Algo == AlgoExact || sel.Turns <= report.LowerBound)
+			}
+			results <- result{i, sel, run}
+		}(i, c)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report.Runs = make([]PortfolioRun, len(p.Configs))
+	sels := make([]*Selection, len(p.Configs))
+	for r := range results {
+		report.Runs[r.i], sels[r.i] = r.run, r.sel
+		if r.run.Optimal {
+			cancel()
+		}
+	}
+
+	best := -1
+	for i, sel := range sels {
+		if sel == nil {
+			continue
+		}
+		if best == -1 || sel.Turns < sels[best].Turns ||
+			(sel.Turns == sels[best].Turns && sels[best].Partial && !sel.Partial) {
+			best = i
+		}
+	}
+	if best == -1 {
+		for _, r := range report.Runs {
+			if r.Err != nil {
+				return nil, r.Err
+			}
+		}
+		return nil, nil
+	}
+	report.Winner = p.Configs[best]
+	sel := *sels[best]
+	sel.Portfolio = report
+	return &sel, nil
+}
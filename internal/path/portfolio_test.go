@@ -0,0 +1,43 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestPortfolioPicksFewestTurns(t *testing.T) {
+	rng := rand.New(rand.NewSource(40))
+	p := DefaultPortfolio()
+	for iter := 0; iter < 40; iter++ {
+		g := randomGraph(rng, 6+rng.Intn(10), 10+rng.Intn(30))
+		opts := Options{Ants: 1 + rng.Intn(20)}
+		sel, err := Find(context.Background(), AlgoPortfolio, g, opts)
+		if err == ErrNoPath {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep := sel.Portfolio
+		if rep == nil || len(rep.Runs) != len(p.Configs) {
+			t.Fatalf("iter %d: report %+v", iter, rep)
+		}
+
+		exact, err := Find(context.Background(), AlgoExact, g, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.LowerBound > exact.Turns {
+			t.Fatalf("iter %d: lower bound %d above the optimum %d", iter, rep.LowerBound, exact.Turns)
+		}
+		if sel.Turns != exact.Turns {
+			t.Fatalf("iter %d: portfolio %d turns (winner %v), optimum %d", iter, sel.Turns, rep.Winner, exact.Turns)
+		}
+		for _, r := range rep.Runs {
+			if r.Err == nil && !r.Cancelled && r.Turns < sel.Turns {
+				t.Fatalf("iter %d: %v ran %d turns, better than the winner's %d", iter, r.Config, r.Turns, sel.Turns)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+
+	"lem-in/internal/model"
+)
+
+// RoomFailure is the colony's fallback when one room of the chosen paths fails.
+type RoomFailure struct {
+	Room   string
+	Turns  int           // turns on the replacement paths; 0 without a plan
+	Impact int           // extra turns over the intact map, never negative
+	Paths  []*model.Path // replacement paths; nil without a plan
+	Err    error         // why there is no plan: ErrNoPath, or ErrUnsatisfiable for a lost via room
+}
+
+// Disconnected reports whether no plan survives the room's loss: End is cut
+// off from Start, or (see Err) the Avoid and Via options can no longer be met.
+func (f RoomFailure) Disconnected() bool { return f.Paths == nil }
+
+// ResilienceReport is the single-room failure analysis of a selection.
+type ResilienceReport struct {
+	Turns    int           // turns with every room intact
+	Failures []RoomFailure // worst first: disconnections, then by Impact
+}
+
+/*
+Resilience removes each intermediate room of sel's paths in turn and re-solves
+the map without it with the finder that chose sel (sel.Algo) and the options
+it ran with, Avoid and Via included; opts.Ants is the ant count. Both turn
+counts then come from the same solver. A heuristic finder can still do better
+on the smaller map by chance; that counts as no impact. Losing a via room
+leaves no plan.
+
+The failures are solved in parallel, one worker and Workspace per CPU, and
+sorted by impact: rooms whose loss leaves no plan first, then by extra turns,
+then by name. It returns ctx.Err() if ctx ends before every failure was
+solved, and the first error that is neither ErrNoPath nor ErrUnsatisfiable.
+*/
+func Resilience(ctx context.Context, g *model.Graph, sel *Selection, opts Options) (*ResilienceReport, error) {
+	var rooms []*model.Room
+	seen := map[*model.Room]bool{}
+	for _, p := range sel.Paths {
+		for _, r := range p.Rooms {
+			if r != g.Start && r != g.End && !seen[r] {
+				seen[r] = true
+				rooms = append(rooms, r)
+			}
+		}
+	}
+
+	rep := &ResilienceReport{Turns: sel.Turns, Failures: make([]RoomFailure, len(rooms))}
+	errs := make([]error, len(rooms))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(rooms)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o := opts
+			o.Workspace = NewWorkspace()
+			for i := range jobs {
+				rep.Failures[i], errs[i] = withoutRoom(ctx, g, rooms[i], sel, o)
+			}
+		}()
+	}
+feed:
+	for i := range rooms {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(rep.Failures, func(i, j int) bool {
+		a, b := rep.Failures[i], rep.Failures[j]
+		if a.Disconnected() != b.Disconnected() {
+			return a.Disconnected()
+		}
+		if a.Impact != b.Impact {
+			return a.Impact > b.Impact
+		}
+		return a.Room < b.Room
+	})
+	return rep, nil
+}
+
+// withoutRoom solves g with room r removed (see Resilience).
+func withoutRoom(ctx context.Context, g *model.Graph, r *model.Room, sel *Selection, opts Options) (RoomFailure, error) {
+	f := RoomFailure{Room: r.Name}
+	if slices.Contains(opts.Via, r.Name) {
+		f.Err = fmt.Errorf("%w: waypoint %s is gone", ErrUnsatisfiable, r.Name)
+		return f, nil
+	}
+	view := subgraph(g, func(x *model.Room) bool { return x != r })
+	view.Start, view.End = view.Rooms[g.Start.Name], view.Rooms[g.End.Name]
+	got, err := Find(ctx, sel.Algo, view, opts)
+	if errors.Is(err, ErrNoPath) || errors.Is(err, ErrUnsatisfiable) {
+		f.Err = err
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	f.Paths = onto(g, got.Paths)
+	f.Turns = got.Turns
+	f.Impact = max(0, f.Turns-sel.Turns)
+	return f, nil
+}
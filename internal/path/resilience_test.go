@@ -0,0 +1,103 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestResilienceMatchesSequentialResolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for iter := 0; iter < 60; iter++ {
+		rooms := 4 + rng.Intn(16)
+		g := randomGraph(rng, rooms, rooms+rng.Intn(3*rooms))
+		algo := []string{AlgoEdmondsKarp, AlgoGreedy, AlgoMinCost}[iter%3]
+		opts := Options{Ants: 1 + rng.Intn(20)}
+		if iter%2 == 1 {
+			opts.Avoid = []string{fmt.Sprintf("r%d", 1+rng.Intn(rooms-2))}
+		}
+		sel, err := Find(context.Background(), algo, g, opts)
+		if err != nil {
+			continue
+		}
+		rep, err := Resilience(context.Background(), g, sel, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, f := range rep.Failures {
+			if i > 0 {
+				prev := rep.Failures[i-1]
+				if !prev.Disconnected() && (f.Disconnected() || prev.Impact < f.Impact) {
+					t.Fatalf("iter %d: %+v sorted before %+v", iter, prev, f)
+				}
+			}
+			if f.Impact < 0 || f.Impact != max(0, f.Turns-sel.Turns) {
+				t.Fatalf("iter %d: without %s: impact %d for %d turns, intact %d", iter, f.Room, f.Impact, f.Turns, sel.Turns)
+			}
+
+			// the same map without the room, solved on its own by the same finder
+			h := subgraph(g, func(r *model.Room) bool { return r.Name != f.Room })
+			h.Start, h.End = h.Rooms[g.Start.Name], h.Rooms[g.End.Name]
+			want, err := Find(context.Background(), algo, h, opts)
+			if f.Disconnected() != (err != nil) {
+				t.Fatalf("iter %d: without %s disconnected=%v, re-solve err %v", iter, f.Room, f.Disconnected(), err)
+			}
+			if f.Disconnected() {
+				continue
+			}
+			if f.Turns != want.Turns || !reflect.DeepEqual(names(f.Paths), names(want.Paths)) {
+				t.Fatalf("iter %d: without %s: %v in %d turns, re-solve %v in %d", iter, f.Room, names(f.Paths), f.Turns, names(want.Paths), want.Turns)
+			}
+			for _, p := range f.Paths {
+				for _, r := range p.Rooms {
+					if r.Name == f.Room || slices.Contains(opts.Avoid, r.Name) || g.Rooms[r.Name] != r {
+						t.Fatalf("iter %d: replacement %v uses the failed room, an avoided room or a copy", iter, names(f.Paths))
+					}
+				}
+			}
+		}
+	}
+}
+
+// Losing a via room leaves no plan; every other failure still passes it.
+func TestResilienceKeepsWaypoints(t *testing.T) {
+	g := gridGraph(5, 3)
+	via := "r2_1" // the middle of the grid
+	opts := Options{Ants: 6, Via: []string{via}}
+	sel, err := Find(context.Background(), AlgoEdmondsKarp, g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Resilience(context.Background(), g, sel, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range rep.Failures {
+		if f.Room == via {
+			if !f.Disconnected() || !errors.Is(f.Err, ErrUnsatisfiable) {
+				t.Errorf("without the waypoint %s: %+v", via, f)
+			}
+			continue
+		}
+		if !f.Disconnected() && !onPaths(f.Paths, g.Rooms[via]) {
+			t.Errorf("without %s: %v skips the waypoint %s", f.Room, names(f.Paths), via)
+		}
+	}
+}
+
+func TestResilienceCancelled(t *testing.T) {
+	g := gridGraph(6, 4)
+	sel := BestPaths(g, 10, 0)
+	sel.Algo = AlgoEdmondsKarp
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Resilience(ctx, g, sel, Options{Ants: 10}); err != context.Canceled {
+		t.Fatalf("err %v, want context.Canceled", err)
+	}
+}
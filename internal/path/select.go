@@ -0,0 +1,172 @@
+package path
+
+import (
+	"context"
+	"io"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+// Candidate is one flow level evaluated by BestPaths.
+type Candidate struct {
+	Flow  int // number of disjoint paths in the set
+	Turns int // turns the scheduler needs with this set
+}
+
+// Selection is the path set chosen by BestPaths together with the
+// candidates it was compared against.
+type Selection struct {
+	Paths      []*model.Path
+	Flow       int // flow level of the chosen set
+	Turns      int // turns needed for the chosen set
+	Candidates []Candidate
+	Scheduled  *scheduler.Schedule // set by finders that schedule the ants themselves; used instead of Simulate
+	Partial    bool                // the context ended first: best set found so far, not the final answer
+	Algo       string              // PathFinder that produced it (set by Find)
+	Settings   string              // options it ran with, enough to reproduce the result
+	Portfolio  *PortfolioReport    // set by the portfolio finder: winner and per-run timings
+	Expanded   int                 // nodes expanded by the augmenting-path searches (flow-based finders)
+}
+
+// Schedule moves ants along the selection. A finder that scheduled the ants
+// itself (Scheduled) keeps its moves, and that schedule is returned as is;
+// otherwise scheduler.Simulate balances them over Paths, which it sorts by
+// length. The CLI, the visualizer and
+// pkg/lemin all schedule through here, so they agree move for move.
+func (s *Selection) Schedule(ants int, g *model.Graph) (*scheduler.Schedule, error) {
+	return s.ScheduleContext(context.Background(), ants, g)
+}
+
+// ScheduleContext is Schedule that stops simulating when ctx is done,
+// returning the turns so far together with ctx.Err().
+func (s *Selection) ScheduleContext(ctx context.Context, ants int, g *model.Graph) (*scheduler.Schedule, error) {
+	if s.Scheduled != nil {
+		return s.Scheduled, nil
+	}
+	return scheduler.SimulateContext(ctx, ants, s.Paths, g)
+}
+
+// WriteSchedule writes the moves Schedule would return, one line per turn,
+// without building them: a Scheduled selection is written out and anything
+// else is streamed by scheduler.Stream, which holds no per-ant state. It
+// returns the number of turns written.
+func (s *Selection) WriteSchedule(w io.Writer, ants int) (int, error) {
+	if s.Scheduled != nil {
+		return s.Scheduled.Len(), s.Scheduled.WriteText(w)
+	}
+	return scheduler.Stream(w, ants, s.Paths)
+}
+
+/*
+BestPaths is MultiPath with turn-optimal selection.
+
+Taking the maximum flow is not always best: with few ants a single short path
+can beat three long ones, and an augmentation may reroute earlier paths into
+longer ones. So after every augmentation we decompose the current flow, ask the
+scheduler's (L-1) formula how many turns `ants` would need on that set, and keep
+the cheapest. On ties the smaller flow level wins (fewer paths, same turns).
+*/
+func BestPaths(g *model.Graph, ants, maxPaths int) *Selection {
+	return BestPathsContext(context.Background(), g, ants, maxPaths)
+}
+
+// BestPathsContext is BestPaths that stops when ctx is done, returning the
+// best set found so far with Partial set (nil if none was found yet).
+func BestPathsContext(ctx context.Context, g *model.Graph, ants, maxPaths int) *Selection {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil
+	}
+	return selectBest(ctx, newNetwork(g), (*network).augment, ants, maxPaths)
+}
+
+// selectBest runs augment until the flow is maximal (or maxPaths is reached,
+// or ctx is done) and keeps the path set needing the fewest turns. Each flow
+// level is scored from its path lengths; only the winner's paths are built.
+func selectBest(ctx context.Context, n *network, augment func(*network) int, ants, maxPaths int) *Selection {
+	var sc scorer
+	flow := 0
+	for !sc.stopped(ctx) {
+		pushed := augment(n)
+		if pushed == 0 {
+			break
+		}
+		flow += pushed
+		sc.considerFlow(n, ants, maxPaths)
+		if maxPaths > 0 && flow >= maxPaths {
+			break
+		}
+	}
+	return sc.resultFor(n)
+}
+
+// scorer keeps the cheapest path set seen so far and the list of candidates.
+type scorer struct {
+	best    *Selection
+	cands   []Candidate
+	partial bool
+
+	kept bool // best's paths are still the walk saved in n.kept
+}
+
+// stopped reports whether ctx is done, remembering that the result is partial.
+func (sc *scorer) stopped(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		sc.partial = true
+	}
+	return sc.partial
+}
+
+// consider scores paths with the (L-1) formula; on ties the earlier
+// (smaller) set is kept.
+func (sc *scorer) consider(paths []*model.Path, ants int) {
+	sc.considerTurns(paths, scheduler.TurnCount(ants, paths))
+}
+
+// considerTurns records paths with a turn count computed by the caller.
+func (sc *scorer) considerTurns(paths []*model.Path, turns int) {
+	sc.cands = append(sc.cands, Candidate{Flow: len(paths), Turns: turns})
+	if sc.best == nil || turns < sc.best.Turns {
+		sc.best = &Selection{Paths: paths, Flow: len(paths), Turns: turns}
+	}
+}
+
+// considerFlow scores the current flow on n like consider, from the path
+// lengths alone. A new best only has its decomposition copied aside; resultFor
+// builds its paths, so a run allocates one path set however many it compares.
+func (sc *scorer) considerFlow(n *network, ants, maxPaths int) {
+	n.decompose(maxPaths)
+	k := len(n.lens)
+	n.sorted = append(n.sorted[:0], n.lens...)
+	turns := scheduler.TurnCountLengths(ants, n.sorted)
+	sc.cands = append(sc.cands, Candidate{Flow: k, Turns: turns})
+	if sc.best == nil || turns < sc.best.Turns {
+		sc.best = &Selection{Flow: k, Turns: turns}
+		n.kept = append(n.kept[:0], n.walk...)
+		n.keptLen = append(n.keptLen[:0], n.lens...)
+		sc.kept = true
+	}
+}
+
+// resultFor is result with the search effort on n recorded and, when the best
+// level was kept by considerFlow, its paths built.
+func (sc *scorer) resultFor(n *network) *Selection {
+	if sc.kept {
+		sc.best.Paths = n.pathsOf(n.kept, n.keptLen)
+		sc.kept = false
+	}
+	sel := sc.result()
+	if sel != nil {
+		sel.Expanded = n.expanded
+	}
+	return sel
+}
+
+func (sc *scorer) result() *Selection {
+	if sc.best == nil {
+		return nil
+	}
+	sc.best.Candidates = sc.cands
+	sc.best.Partial = sc.partial
+	return sc.best
+}
@@ -0,0 +1,44 @@
+package path
+
+import (
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+// The second augmentation reroutes s-x-y-e into two 5-link paths, which is
+// worse for a single ant than keeping the 3-link path alone.
+func TestBestPathsPrefersFewerPaths(t *testing.T) {
+	g := rerouteTrap()
+
+	if n := len(MultiPath(g, 0)); n != 2 {
+		t.Fatalf("MultiPath found %d paths, want 2", n)
+	}
+	sel := BestPaths(g, 1, 0)
+	if sel.Flow != 1 || sel.Turns != 3 {
+		t.Errorf("chose flow %d with %d turns, want flow 1 with 3 turns", sel.Flow, sel.Turns)
+	}
+	want := []Candidate{{Flow: 1, Turns: 3}, {Flow: 2, Turns: 5}}
+	if len(sel.Candidates) != len(want) || sel.Candidates[0] != want[0] || sel.Candidates[1] != want[1] {
+		t.Errorf("candidates %+v, want %+v", sel.Candidates, want)
+	}
+}
+
+// rerouteTrap is a map whose shortest path s-x-y-e must be taken apart
+// (through the reverse of tunnel x-y) to reach the maximum flow.
+func rerouteTrap() *model.Graph {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 9, 0)
+	for _, r := range []string{"x", "y", "p1", "p2", "p3", "q1", "q2", "q3"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{
+		{"s", "x"}, {"x", "y"}, {"y", "e"},
+		{"s", "p1"}, {"p1", "p2"}, {"p2", "p3"}, {"p3", "y"},
+		{"x", "q1"}, {"q1", "q2"}, {"q2", "q3"}, {"q3", "e"},
+	} {
+		g.AddLink(l[0], l[1])
+	}
+	return g
+}
@@ -0,0 +1,191 @@
+package path
+
+import (
+	"context"
+	"fmt"
+
+	"lem-in/internal/model"
+)
+
+/*
+Session keeps MultiPath's residual graph between edits so that adding or
+removing a single tunnel does not rebuild and re-solve the whole network.
+
+  - AddLink appends the two link arcs and augments from the current flow; a
+    new tunnel raises the flow by at most one.
+  - RemoveLink cancels the unit of flow on each of the tunnel's arcs, if any:
+    the unit is walked forward to End and back to Start (or around its cycle)
+    and taken off, then augmenting repairs the flow. Paths that did not use
+    the tunnel are left alone.
+  - RemoveRoom removes every tunnel of the room, then closes the room.
+
+The result is a maximum flow of the edited graph, so Flow and Cut are the
+same as a fresh MultiPath/MinCut. That flow is only one of the maximum flows,
+though, and BestPaths' turn-optimal choice depends on the order in which
+augmentations reroute each other, so Select re-runs the selection on the
+edited graph (in the session's own workspace, once per edit and ant count).
+Its paths and turns are exactly those of a fresh BestPaths.
+
+A Session edits the graph it was created with; edit the graph through the
+session only.
+*/
+type Session struct {
+	n    *network
+	flow int
+
+	ws      Workspace  // for Select's fresh solves
+	sel     *Selection // Select's result for selAnts; nil after an edit
+	selAnts int
+}
+
+// NewSession builds the network for g and runs the max flow once.
+func NewSession(g *model.Graph) (*Session, error) {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil, ErrNoPath
+	}
+	s := &Session{n: newNetwork(g)}
+	s.saturate()
+	return s, nil
+}
+
+// Flow is the current number of room-disjoint paths.
+func (s *Session) Flow() int { return s.flow }
+
+// Select returns BestPaths(g, ants, 0) for the edited graph. The result is
+// shared until the next edit; do not modify it.
+func (s *Session) Select(ants int) *Selection {
+	if s.sel == nil || s.selAnts != ants {
+		s.sel = selectBest(context.Background(), s.ws.network(s.n.g), (*network).augment, ants, 0)
+		s.selAnts = ants
+	}
+	return s.sel
+}
+
+// Cut is the minimum cut of the current flow (see MinCut).
+func (s *Session) Cut() *Cut { return s.n.minCut(s.flow) }
+
+// AddLink adds the tunnel a—b and augments.
+func (s *Session) AddLink(a, b string) error {
+	g := s.n.g
+	if _, ok := g.Rooms[a]; !ok {
+		return fmt.Errorf("unknown room %q", a)
+	}
+	if _, ok := g.Rooms[b]; !ok {
+		return fmt.Errorf("unknown room %q", b)
+	}
+	if !g.AddLink(a, b) {
+		return fmt.Errorf("cannot link %s-%s", a, b)
+	}
+	s.n.addEdge(s.n.out(a), s.n.in(b), 1, 1)
+	s.n.addEdge(s.n.out(b), s.n.in(a), 1, 1)
+	s.saturate()
+	return nil
+}
+
+// RemoveLink removes the tunnel a—b, cancels the flow it carried and repairs.
+func (s *Session) RemoveLink(a, b string) error {
+	if !s.n.g.RemoveLink(a, b) {
+		return fmt.Errorf("no link %s-%s", a, b)
+	}
+	s.closeLink(a, b)
+	s.saturate()
+	return nil
+}
+
+// RemoveRoom removes a room other than Start and End with all its tunnels,
+// cancels the flow through it and repairs.
+func (s *Session) RemoveRoom(name string) error {
+	g := s.n.g
+	r, ok := g.Rooms[name]
+	if !ok {
+		return fmt.Errorf("unknown room %q", name)
+	}
+	if r == g.Start || r == g.End {
+		return fmt.Errorf("cannot remove start or end room %q", name)
+	}
+	for _, nb := range r.Links {
+		s.closeLink(name, nb.Name)
+	}
+	g.RemoveRoom(name)
+	for _, ei := range s.n.adj[s.n.in(name)] {
+		if ei%2 == 0 && s.n.edges[ei].to == s.n.out(name) {
+			s.n.edges[ei].cap = 0
+		}
+	}
+	s.saturate()
+	return nil
+}
+
+// saturate augments until no augmenting path is left. Every edit ends here,
+// so it also drops Select's cached result.
+func (s *Session) saturate() {
+	s.sel = nil
+	for {
+		pushed := s.n.augment()
+		if pushed == 0 {
+			return
+		}
+		s.flow += pushed
+	}
+}
+
+// closeLink zeroes the capacity of the live arcs a_out→b_in and b_out→a_in
+// and cancels the flow they carry.
+func (s *Session) closeLink(a, b string) {
+	n := s.n
+	for _, d := range [][2]string{{a, b}, {b, a}} {
+		u, v := n.out(d[0]), n.in(d[1])
+		for _, ei := range n.adj[u] {
+			e := &n.edges[ei]
+			if ei%2 != 0 || e.to != v || e.cap == 0 {
+				continue
+			}
+			if e.flow > 0 {
+				n.push(ei, -1)
+				s.cancel(u, v)
+			}
+			e.cap = 0
+		}
+	}
+}
+
+// cancel takes off the unit of flow that used to go u→v. v is left with one
+// unit too many going out, u with one too many coming in: follow the flow
+// from v to End, or around its cycle back to u, and from u back to Start,
+// removing the unit on the way.
+func (s *Session) cancel(u, v int) {
+	n := s.n
+	x := v
+	for x != n.sink && x != u {
+		next := -1
+		for _, ei := range n.adj[x] {
+			if n.edges[ei].flow > 0 {
+				next = ei
+				break
+			}
+		}
+		if next == -1 {
+			return // should not happen in a consistent flow
+		}
+		n.push(next, -1)
+		x = n.edges[next].to
+	}
+	if x == u {
+		return // the unit was a cycle; the flow value is unchanged
+	}
+	for x = u; x != n.source; {
+		prev := -1
+		for _, ri := range n.adj[x] {
+			if n.edges[ri].flow < 0 { // reverse arc of an incoming flow arc
+				prev = ri ^ 1
+				break
+			}
+		}
+		if prev == -1 {
+			return
+		}
+		n.push(prev, -1)
+		x = n.edges[prev^1].to
+	}
+	s.flow--
+}
@@ -0,0 +1,88 @@
+package path
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"lem-in/internal/model"
+	"lem-in/internal/scheduler"
+)
+
+func TestSessionMatchesFullRecompute(t *testing.T) {
+	rng := rand.New(rand.NewSource(37))
+	for iter := 0; iter < 60; iter++ {
+		rooms := 6 + rng.Intn(14)
+		g := randomGraph(rng, rooms, 10+rng.Intn(40))
+		s, err := NewSession(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for step := 0; step < 40; step++ {
+			a := fmt.Sprintf("r%d", rng.Intn(rooms))
+			b := fmt.Sprintf("r%d", rng.Intn(rooms))
+			var op string
+			switch rng.Intn(5) {
+			case 0, 1:
+				op, err = "add "+a+"-"+b, s.AddLink(a, b)
+			case 2, 3:
+				op, err = "remove "+a+"-"+b, s.RemoveLink(a, b)
+			default:
+				op, err = "remove "+a, s.RemoveRoom(a)
+			}
+			if err != nil {
+				continue // unknown room, duplicate or missing link
+			}
+
+			want := MinCut(g)
+			if got := s.Cut(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("iter %d step %d (%s): cut %+v, full recompute %+v", iter, step, op, got, want)
+			}
+			if s.Flow() != want.Flow {
+				t.Fatalf("iter %d step %d (%s): flow %d, want %d", iter, step, op, s.Flow(), want.Flow)
+			}
+			ants := 1 + rng.Intn(50)
+			sel, fresh := s.Select(ants), BestPaths(g, ants, 0)
+			if (sel == nil) != (fresh == nil) {
+				t.Fatalf("iter %d step %d (%s): selection %v, fresh %v", iter, step, op, sel, fresh)
+			}
+			if sel == nil {
+				continue
+			}
+			paths := sel.Paths
+			if !reflect.DeepEqual(names(paths), names(fresh.Paths)) || sel.Turns != fresh.Turns {
+				t.Fatalf("iter %d step %d (%s): %v in %d turns, fresh %v in %d", iter, step, op, names(paths), sel.Turns, names(fresh.Paths), fresh.Turns)
+			}
+			if got := scheduler.TurnCount(ants, paths); got != sel.Turns {
+				t.Fatalf("iter %d step %d (%s): paths take %d turns, selection says %d", iter, step, op, got, sel.Turns)
+			}
+			used := map[string]bool{}
+			for _, p := range paths {
+				for i, r := range p.Rooms {
+					if g.Rooms[r.Name] != r {
+						t.Fatalf("iter %d step %d (%s): path uses removed room %s", iter, step, op, r.Name)
+					}
+					if i > 0 && !linked(p.Rooms[i-1], r) {
+						t.Fatalf("iter %d step %d (%s): path uses missing link %s-%s", iter, step, op, p.Rooms[i-1].Name, r.Name)
+					}
+					if r != g.Start && r != g.End {
+						if used[r.Name] {
+							t.Fatalf("iter %d step %d (%s): room %s on two paths", iter, step, op, r.Name)
+						}
+						used[r.Name] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+func linked(a, b *model.Room) bool {
+	for _, l := range a.Links {
+		if l == b {
+			return true
+		}
+	}
+	return false
+}
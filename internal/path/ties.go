@@ -0,0 +1,102 @@
+package path
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"lem-in/internal/model"
+)
+
+// Tie-breaking policies for the max-flow path finders.
+const (
+	TieName      = "name"      // room names in byte order (the default)
+	TieDistance  = "distance"  // Euclidean distance to End, nearest first; then name
+	TieInsertion = "insertion" // order the rooms were added (file order)
+	TieRandom    = "random"    // a shuffle of the rooms seeded with Seed
+)
+
+// TieBreak decides which room is tried first when several are equally good.
+// The max-flow finders number the rooms in this order, so it decides the BFS
+// neighbour order, which equal-length augmenting path wins, and how the flow
+// is split into paths. A policy (and seed) always gives the same result.
+type TieBreak struct {
+	Policy string // one of the Tie* constants; empty means TieName
+	Seed   int64  // used by TieRandom only
+}
+
+// String renders the policy as "ties=<policy>" plus the seed when random.
+func (t TieBreak) String() string {
+	s := "ties=" + t.policy()
+	if t.policy() == TieRandom {
+		s += fmt.Sprintf(" seed=%d", t.Seed)
+	}
+	return s
+}
+
+func (t TieBreak) policy() string {
+	if t.Policy == "" {
+		return TieName
+	}
+	return t.Policy
+}
+
+// TiePolicies lists the accepted TieBreak policies.
+func TiePolicies() []string {
+	return []string{TieName, TieDistance, TieInsertion, TieRandom}
+}
+
+// order returns g's room names in the policy's order.
+func (t TieBreak) order(g *model.Graph) ([]string, error) {
+	return t.appendOrder(make([]string, 0, len(g.Rooms)), g)
+}
+
+// appendOrder is order appending to names, which must be empty.
+func (t TieBreak) appendOrder(names []string, g *model.Graph) ([]string, error) {
+	for name := range g.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch t.policy() {
+	case TieName:
+	case TieDistance:
+		dist := func(r *model.Room) float64 {
+			// squared: same order; in float64 so far-apart rooms cannot overflow
+			dx, dy := float64(r.X)-float64(g.End.X), float64(r.Y)-float64(g.End.Y)
+			return dx*dx + dy*dy
+		}
+		sort.SliceStable(names, func(i, j int) bool {
+			return dist(g.Rooms[names[i]]) < dist(g.Rooms[names[j]])
+		})
+	case TieInsertion:
+		sort.SliceStable(names, func(i, j int) bool {
+			return g.Rooms[names[i]].Order < g.Rooms[names[j]].Order
+		})
+	case TieRandom:
+		rng := rand.New(rand.NewSource(t.Seed))
+		rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	default:
+		return nil, fmt.Errorf("unknown tie-breaking policy %q (want one of name, distance, insertion, random)", t.Policy)
+	}
+	return names, nil
+}
+
+// network builds the room-disjoint flow network with ids in the policy's
+// order, inside ws's buffers when ws is not nil.
+func (t TieBreak) network(g *model.Graph, ws *Workspace) (*network, error) {
+	if ws == nil {
+		names, err := t.order(g)
+		if err != nil {
+			return nil, err
+		}
+		return buildOrdered(g, 1, names), nil
+	}
+	names, err := t.appendOrder(ws.names[:0], g)
+	if err != nil {
+		return nil, err
+	}
+	ws.names = names
+	ws.n.init(g, 1, names)
+	return &ws.n, nil
+}
@@ -0,0 +1,118 @@
+package path
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func TestTieBreakPicksAmongEqualPaths(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.AddRoom("b", 1, 5) // added first, but far from End
+	g.AddRoom("a", 1, 9)
+	g.AddRoom("c", 1, 1)
+	g.End = g.AddRoom("e", 2, 0)
+	for _, r := range []string{"a", "b", "c"} {
+		g.AddLink("s", r)
+		g.AddLink(r, "e")
+	}
+	for _, tc := range []struct {
+		ties TieBreak
+		want string
+	}{
+		{TieBreak{}, "a"},
+		{TieBreak{Policy: TieName}, "a"},
+		{TieBreak{Policy: TieInsertion}, "b"},
+		{TieBreak{Policy: TieDistance}, "c"},
+	} {
+		sel, err := Find(context.Background(), AlgoEdmondsKarp, g, Options{Ants: 1, Ties: tc.ties})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sel.Paths[0].Rooms[1].Name; got != tc.want {
+			t.Errorf("%v: path through %s, want %s", tc.ties, got, tc.want)
+		}
+	}
+	if _, err := Find(context.Background(), AlgoEdmondsKarp, g, Options{Ants: 1, Ties: TieBreak{Policy: "nope"}}); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
+
+func TestTieBreakIsDeterministic(t *testing.T) {
+	rng := rand.New(rand.NewSource(39))
+	for iter := 0; iter < 30; iter++ {
+		g := randomGraph(rng, 8+rng.Intn(20), 20+rng.Intn(60))
+		flow := len(MultiPath(g, 0))
+		for _, algo := range []string{AlgoEdmondsKarp, AlgoMinCost, AlgoDinic} {
+			for _, ties := range []TieBreak{{Policy: TieName}, {Policy: TieDistance}, {Policy: TieInsertion}, {Policy: TieRandom, Seed: 7}} {
+				opts := Options{Ants: 1 + rng.Intn(30), Ties: ties}
+				first, err1 := Find(context.Background(), algo, g, opts)
+				again, err2 := Find(context.Background(), algo, g, opts)
+				if err1 != err2 || (err1 == nil && !reflect.DeepEqual(names(first.Paths), names(again.Paths))) {
+					t.Fatalf("iter %d %s %v: runs differ", iter, algo, ties)
+				}
+				if err1 == nil && maxCandidate(first) != flow {
+					t.Fatalf("iter %d %s %v: max flow %d, want %d", iter, algo, ties, maxCandidate(first), flow)
+				}
+			}
+		}
+	}
+}
+
+func names(paths []*model.Path) [][]string {
+	var out [][]string
+	for _, p := range paths {
+		var rs []string
+		for _, r := range p.Rooms {
+			rs = append(rs, r.Name)
+		}
+		out = append(out, rs)
+	}
+	return out
+}
+
+func maxCandidate(sel *Selection) int {
+	max := 0
+	for _, c := range sel.Candidates {
+		if c.Flow > max {
+			max = c.Flow
+		}
+	}
+	return max
+}
+
+// Far-apart coordinates must not overflow the squared distance.
+func TestTieDistanceFarRooms(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	g.AddRoom("far", 3_037_000_500, 0) // its squared distance exceeds MaxInt64
+	g.AddRoom("near", 1, 0)
+	names, err := TieBreak{Policy: TieDistance}.order(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"e", "s", "near", "far"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("order %v, want %v", names, want)
+	}
+}
+
+// Only the finders that number rooms by the tie policy report it.
+func TestSettingsReportTiesOnlyWhenUsed(t *testing.T) {
+	g := gridGraph(3, 2)
+	for _, name := range []string{AlgoEdmondsKarp, AlgoMinCost, AlgoDinic, AlgoAStar, AlgoGreedy, AlgoExhaustive, AlgoExact} {
+		sel, err := Find(context.Background(), name, g, Options{Ants: 3, Ties: TieBreak{Policy: TieDistance}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := name != AlgoGreedy && name != AlgoExhaustive && name != AlgoExact
+		if got := strings.Contains(sel.Settings, "ties="); got != want {
+			t.Errorf("%s: settings %q, reporting ties %v, want %v", name, sel.Settings, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"lem-in/internal/model"
+)
+
+// Trace records every BFS augmentation of MultiPath's max flow, for teaching
+// and debugging: it shows where an augmenting path re-routes earlier paths by
+// walking a tunnel against its flow.
+type Trace struct {
+	Steps []TraceStep `json:"steps"`
+}
+
+// TraceStep is one augmentation.
+type TraceStep struct {
+	Path     []string    `json:"path"`     // augmenting path in room names, Start to End
+	Reversed [][2]string `json:"reversed"` // tunnels u->v whose flow the path cancelled by going v->u
+	Flow     int         `json:"flow"`     // flow value after the step
+}
+
+// TraceMultiPath runs MultiPath's Edmonds–Karp augmentations on g with the
+// recorder on and returns the trace.
+func TraceMultiPath(g *model.Graph, maxPaths int) *Trace {
+	t, _ := TraceFlow(context.Background(), g, Options{MaxPaths: maxPaths})
+	return t
+}
+
+// TraceFlow is TraceMultiPath with the network numbered by opts.Ties, built
+// in opts.Workspace when set, and stopped at opts.MaxPaths. It is always the
+// Edmonds–Karp flow, whichever finder chose the paths. When ctx ends it
+// returns the steps so far together with ctx.Err().
+func TraceFlow(ctx context.Context, g *model.Graph, opts Options) (*Trace, error) {
+	t := &Trace{Steps: []TraceStep{}}
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return t, nil
+	}
+	n, err := opts.Ties.network(g, opts.Workspace)
+	if err != nil {
+		return t, err
+	}
+	n.trace = t
+	defer func() { n.trace = nil }()
+	flow := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return t, err
+		}
+		pushed := n.augment()
+		if pushed == 0 {
+			break
+		}
+		flow += pushed
+		if opts.MaxPaths > 0 && flow >= opts.MaxPaths {
+			break
+		}
+	}
+	return t, nil
+}
+
+// record appends the augmenting path given by arcs (Start to End order).
+func (n *network) record(arcs []int, pushed int) {
+	step := TraceStep{Path: []string{n.g.Start.Name}, Reversed: [][2]string{}}
+	if k := len(n.trace.Steps); k > 0 {
+		step.Flow = n.trace.Steps[k-1].Flow
+	}
+	step.Flow += pushed
+	for _, ei := range arcs {
+		e := n.edges[ei]
+		from := n.edges[ei^1].to
+		to := n.roomOf(e.to)
+		if to != step.Path[len(step.Path)-1] {
+			step.Path = append(step.Path, to)
+		}
+		// odd arcs are reverse arcs; between two rooms they undo a tunnel's flow
+		if ei%2 == 1 && from/2 != e.to/2 {
+			step.Reversed = append(step.Reversed, [2]string{to, n.roomOf(from)})
+		}
+	}
+	n.trace.Steps = append(n.trace.Steps, step)
+}
+
+// WriteText renders the trace one augmentation per line.
+func (t *Trace) WriteText(w io.Writer) error {
+	for i, s := range t.Steps {
+		line := fmt.Sprintf("step %d: %s, flow %d", i+1, strings.Join(s.Path, " -> "), s.Flow)
+		if len(s.Reversed) > 0 {
+			var undo []string
+			for _, r := range s.Reversed {
+				undo = append(undo, r[0]+"->"+r[1])
+			}
+			line += " (cancels " + strings.Join(undo, ", ") + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package path
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTraceShowsReroute(t *testing.T) {
+	tr := TraceMultiPath(rerouteTrap(), 0)
+	want := []TraceStep{
+		{Path: []string{"s", "x", "y", "e"}, Reversed: [][2]string{}, Flow: 1},
+		{Path: []string{"s", "p1", "p2", "p3", "y", "x", "q1", "q2", "q3", "e"}, Reversed: [][2]string{{"x", "y"}}, Flow: 2},
+	}
+	if !reflect.DeepEqual(tr.Steps, want) {
+		t.Fatalf("steps %+v, want %+v", tr.Steps, want)
+	}
+
+	var sb strings.Builder
+	if err := tr.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "step 2: s -> p1 -> p2 -> p3 -> y -> x -> q1 -> q2 -> q3 -> e, flow 2 (cancels x->y)") {
+		t.Errorf("text trace:\n%s", sb.String())
+	}
+
+	js, _ := json.Marshal(tr)
+	if !strings.Contains(string(js), `"reversed":[["x","y"]]`) {
+		t.Errorf("json trace: %s", js)
+	}
+}
@@ -0,0 +1,73 @@
+package path
+
+import (
+	"context"
+
+	"lem-in/internal/model"
+)
+
+/*
+Workspace holds the residual graph and search buffers of MultiPath so they
+can be reused. Each solve rebuilds the network inside the slices, map and
+queues left by the previous one, so once a workspace has seen a map of a
+given size, solving maps up to that size allocates little more than the
+returned paths.
+
+The flow-based finders (edmonds-karp, mincost, dinic and the A* variants)
+solve inside Options.Workspace when it is set, so Find reuses one too: the
+BFS queue, the min-cost and A* heaps and distances, the A* costs and Dinic's
+levels all live in the network (BenchmarkFind, with -benchmem).
+
+A Workspace is not safe for concurrent use; keep one per goroutine or hand
+them out from a sync.Pool. The results never alias the workspace.
+*/
+type Workspace struct {
+	n     network
+	names []string
+}
+
+// NewWorkspace returns an empty workspace; it grows on first use.
+func NewWorkspace() *Workspace {
+	return &Workspace{}
+}
+
+// network rebuilds the room-disjoint network for g, ids in name order as in
+// newNetwork.
+func (w *Workspace) network(g *model.Graph) *network {
+	n, _ := TieBreak{}.network(g, w) // the name policy cannot fail
+	return n
+}
+
+// MultiPath is MultiPath using the workspace's buffers.
+func (w *Workspace) MultiPath(g *model.Graph, maxPaths int) []*model.Path {
+	paths, _ := w.MultiPathContext(context.Background(), g, maxPaths)
+	return paths
+}
+
+// MultiPathContext is MultiPathContext using the workspace's buffers.
+func (w *Workspace) MultiPathContext(ctx context.Context, g *model.Graph, maxPaths int) ([]*model.Path, bool) {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil, false
+	}
+	return w.network(g).multiPath(ctx, maxPaths)
+}
+
+// MinCut is MinCut using the workspace's buffers.
+func (w *Workspace) MinCut(g *model.Graph) *Cut {
+	cut, _ := w.MinCutContext(context.Background(), g)
+	return cut
+}
+
+// MinCutContext is MinCut that gives up when ctx is done: a flow cut short
+// has no minimum cut to read, so it returns nil and ctx.Err().
+func (w *Workspace) MinCutContext(ctx context.Context, g *model.Graph) (*Cut, error) {
+	if g == nil || g.Start == nil || g.End == nil || len(g.Rooms) == 0 {
+		return nil, nil
+	}
+	n := w.network(g)
+	flow, partial := n.maxFlow(ctx, 0)
+	if partial {
+		return nil, ctx.Err()
+	}
+	return n.minCut(flow), nil
+}
@@ -0,0 +1,92 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// One workspace reused across maps of varying size must give exactly the
+// results of fresh solves.
+func TestWorkspaceMatchesFreshSolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(44))
+	w := NewWorkspace()
+	for iter := 0; iter < 200; iter++ {
+		g := randomGraph(rng, 2+rng.Intn(30), rng.Intn(80))
+		maxPaths := rng.Intn(3)
+		if got, want := names(w.MultiPath(g, maxPaths)), names(MultiPath(g, maxPaths)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("iter %d: workspace %v, fresh %v", iter, got, want)
+		}
+		if got, want := w.MinCut(g), MinCut(g); !reflect.DeepEqual(got, want) {
+			t.Fatalf("iter %d: workspace cut %+v, fresh %+v", iter, got, want)
+		}
+	}
+}
+
+// Find in a shared workspace must match Find without one, for every finder
+// and tie policy that builds its network there.
+func TestFindInWorkspaceMatchesFresh(t *testing.T) {
+	rng := rand.New(rand.NewSource(4444))
+	w := NewWorkspace()
+	algos := []string{AlgoEdmondsKarp, AlgoMinCost, AlgoDinic, AlgoAStar, AlgoAStarWeighted}
+	for iter := 0; iter < 100; iter++ {
+		g := randomGraph(rng, 2+rng.Intn(30), rng.Intn(80))
+		for _, algo := range algos {
+			opts := Options{Ants: 1 + rng.Intn(20), Ties: TieBreak{Policy: TiePolicies()[rng.Intn(4)], Seed: rng.Int63()}}
+			want, werr := Find(context.Background(), algo, g, opts)
+			opts.Workspace = w
+			got, gerr := Find(context.Background(), algo, g, opts)
+			if werr != nil || gerr != nil {
+				if werr != gerr {
+					t.Fatalf("iter %d %s: workspace err %v, fresh %v", iter, algo, gerr, werr)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(names(got.Paths), names(want.Paths)) || got.Turns != want.Turns || got.Expanded != want.Expanded {
+				t.Fatalf("iter %d %s %s: workspace %v (%d turns), fresh %v (%d turns)", iter, algo, opts.Ties, names(got.Paths), got.Turns, names(want.Paths), want.Turns)
+			}
+		}
+	}
+}
+
+func BenchmarkMultiPath(b *testing.B) {
+	for _, size := range []struct{ w, h int }{{20, 10}, {50, 20}} {
+		g := gridGraph(size.w, size.h)
+		b.Run(fmt.Sprintf("fresh/%dx%d", size.w, size.h), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				MultiPath(g, 0)
+			}
+		})
+		b.Run(fmt.Sprintf("workspace/%dx%d", size.w, size.h), func(b *testing.B) {
+			b.ReportAllocs()
+			w := NewWorkspace()
+			for i := 0; i < b.N; i++ {
+				w.MultiPath(g, 0)
+			}
+		})
+	}
+}
+
+// BenchmarkFind compares fresh and workspace solves for every finder that
+// builds its network in the workspace; run it with -benchmem.
+func BenchmarkFind(b *testing.B) {
+	g := gridGraph(50, 20)
+	for _, algo := range []string{AlgoEdmondsKarp, AlgoMinCost, AlgoDinic, AlgoAStar, AlgoAStarWeighted} {
+		b.Run(algo+"/fresh", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Find(context.Background(), algo, g, Options{Ants: 100})
+			}
+		})
+		b.Run(algo+"/workspace", func(b *testing.B) {
+			b.ReportAllocs()
+			opts := Options{Ants: 100, Workspace: NewWorkspace()}
+			for i := 0; i < b.N; i++ {
+				Find(context.Background(), algo, g, opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"sort"
+
+	"lem-in/internal/model"
+)
+
+// GroupPaths is one ant group and the paths it may use (from its own start
+// to its own end).
+type GroupPaths struct {
+	Ants  int
+	Paths []*model.Path
+}
+
+// SimulateGroups schedules several ant groups together on one map with the
+// reservation table of SimulateShared, so no intermediate room holds two
+// ants at the end of a turn and no tunnel is crossed twice in a turn, whatever
+// group the ants belong to. Ants are numbered group by group (group 0 gets
+// 1..Ants, and so on). Move.Path indexes the ant's own group's Paths.
+//
+// Ants are placed one at a time: each group offers its next ant on the
+// path/departure that arrives earliest, and the earliest offer is taken
+// (lower group first on ties). Paths must not pass through another group's
+// start or end room; those rooms hold any number of ants.
+func SimulateGroups(groups []GroupPaths) *Schedule {
+	tab := newTable()
+	type placement struct {
+		group, path, depart, arrival int
+	}
+	var plan []placement
+	left := make([]int, len(groups))
+	departed := make([][]map[int]bool, len(groups))
+	next := make([][]int, len(groups)) // earliest departure worth trying
+	for gi, gr := range groups {
+		left[gi] = gr.Ants
+		if len(gr.Paths) == 0 {
+			left[gi] = 0
+		}
+		sort.SliceStable(gr.Paths, func(i, j int) bool { return gr.Paths[i].Length < gr.Paths[j].Length })
+		departed[gi] = make([]map[int]bool, len(gr.Paths))
+		next[gi] = make([]int, len(gr.Paths))
+		for pi := range gr.Paths {
+			departed[gi][pi] = map[int]bool{}
+			next[gi][pi] = 1
+		}
+	}
+
+	turns := 0
+	for {
+		best := placement{group: -1}
+		for gi, gr := range groups {
+			if left[gi] == 0 {
+				continue
+			}
+			for pi, p := range gr.Paths {
+				s := next[gi][pi]
+				for departed[gi][pi][s] || !tab.fits(p, s) {
+					s++
+				}
+				next[gi][pi] = s // reservations only grow
+				if arr := s + p.Length - 1; best.group == -1 || arr < best.arrival {
+					best = placement{gi, pi, s, arr}
+				}
+			}
+		}
+		if best.group == -1 {
+			break
+		}
+		left[best.group]--
+		departed[best.group][best.path][best.depart] = true
+		tab.reserve(groups[best.group].Paths[best.path], best.depart)
+		plan = append(plan, best)
+		if best.arrival > turns {
+			turns = best.arrival
+		}
+	}
+
+	// number the ants group by group, in placement order within a group
+	firstID := make([]int, len(groups))
+	id := 1
+	for gi, gr := range groups {
+		firstID[gi] = id
+		id += gr.Ants
+	}
+	out := &Schedule{Turns: make([][]Move, turns)}
+	for _, pl := range plan {
+		out.place(firstID[pl.group], pl.path, groups[pl.group].Paths[pl.path], pl.depart)
+		firstID[pl.group]++
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Move is one ant crossing one tunnel during a turn.
+type Move struct {
+	Ant  int    // ant ID, from 1
+	From string // room the ant leaves
+	To   string // room the ant enters
+	Path int    // index of the ant's path in the slice the schedule was built from
+}
+
+// String renders the move in the classic "L<ant>-<room>" form.
+func (m Move) String() string { return "L" + strconv.Itoa(m.Ant) + "-" + m.To }
+
+// Schedule is the result of a simulation: Turns[i] holds the moves of turn i+1.
+type Schedule struct {
+	Turns [][]Move
+}
+
+// Len is the number of turns; a nil schedule has none.
+func (s *Schedule) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Turns)
+}
+
+// Lines renders every turn as its "L<ant>-<room>" tokens.
+func (s *Schedule) Lines() [][]string {
+	if s == nil {
+		return nil
+	}
+	lines := make([][]string, len(s.Turns))
+	for i, turn := range s.Turns {
+		lines[i] = make([]string, len(turn))
+		for j, m := range turn {
+			lines[i][j] = m.String()
+		}
+	}
+	return lines
+}
+
+// WriteText writes the schedule in the classic text format: one line per
+// turn, moves separated by single spaces.
+func (s *Schedule) WriteText(w io.Writer) error {
+	if s == nil {
+		return nil
+	}
+	var b strings.Builder
+	for _, turn := range s.Turns {
+		b.Reset()
+		for j, m := range turn {
+			if j > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteByte('L')
+			b.WriteString(strconv.Itoa(m.Ant))
+			b.WriteByte('-')
+			b.WriteString(m.To)
+		}
+		b.WriteByte('\n')
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
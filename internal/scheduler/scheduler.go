@@ -1,75 +1,42 @@
 package scheduler
 
 import (
-	"fmt"
+	"context"
 	"lem-in/internal/model"
 	"sort"
-	"strings"
 )
 
-// Run simulates ants moving along given paths, printing moves each turn in the "Lx-room" format.
+// Simulate moves ants along the given room-disjoint paths and returns every
+// turn's moves. It sorts paths by length in place; Move.Path indexes the
+// sorted slice.
+func Simulate(ants int, paths []*model.Path, g *model.Graph) *Schedule {
+	s, _ := SimulateContext(context.Background(), ants, paths, g)
+	return s
+}
+
+// SimulateContext is Simulate that checks ctx every turn. When ctx is done it
+// returns the turns simulated so far together with ctx.Err().
 //
 // KEY RULES of "lem-in":
-// 1) Each turn prints a space-separated list of moves "L<antID>-<roomName>".
+// 1) Each turn is a list of moves "L<antID>-<roomName>" (see Schedule.WriteText).
 // 2) A room (except start and end) can contain at most one ant at a time (room exclusivity).
 // 3) Multiple ants may leave the start in the same turn (one per chosen path if the first room is free).
 // 4) Multiple ants may reach the end in the same turn.
 // 5) Edges do NOT need to be locked: the constraint is on rooms, not edges.
 // 6) Makespan is minimised with (L-1) balancing: find minimal T with Σ max(0, T - (L_i - 1)) ≥ ants.
-func Run(ants int, paths []*model.Path, g *model.Graph) {
+func SimulateContext(ctx context.Context, ants int, paths []*model.Path, g *model.Graph) (*Schedule, error) {
 	if ants <= 0 || len(paths) == 0 {
-		return
+		return &Schedule{}, nil
 	}
 
 	// Sort paths by length ascending (shorter first)
-	sort.Slice(paths, func(i, j int) bool {
+	sort.SliceStable(paths, func(i, j int) bool {
 		return paths[i].Length < paths[j].Length
 	})
 
-	// Gather lengths (in edges)
-	lens := make([]int, len(paths))
-	for i, p := range paths {
-		lens[i] = p.Length
-	}
-
 	// ---- Optimal pre-allocation (L-1 formula) ----
-	T := lens[0] - 1
-	if T < 0 {
-		T = 0
-	}
-	for {
-		sum := 0
-		for _, L := range lens {
-			base := L - 1
-			if T > base {
-				sum += T - base
-			}
-		}
-		if sum >= ants {
-			break
-		}
-		T++
-	}
-
+	counts := Assign(ants, paths)
 	assigned := make([][]int, len(paths)) // IDs per path
-	counts := make([]int, len(paths))
-	remain := ants
-	for i, L := range lens {
-		base := L - 1
-		take := 0
-		if T > base {
-			take = T - base
-		}
-		if take > remain {
-			take = remain
-		}
-		counts[i] = take
-		remain -= take
-	}
-	for i := 0; i < len(paths) && remain > 0; i++ {
-		counts[i]++
-		remain--
-	}
 
 	// Materialise queues 1..ants
 	id := 1
@@ -107,9 +74,13 @@ func Run(ants int, paths []*model.Path, g *model.Graph) {
 	}
 
 	finished := 0
+	sched := &Schedule{}
 
 	for finished < ants {
-		var moves []string
+		if err := ctx.Err(); err != nil {
+			return sched, err
+		}
+		var moves []Move
 
 		// Move existing ants forward (back-to-front per path)
 		for pi, p := range paths {
@@ -138,7 +109,7 @@ func Run(ants int, paths []*model.Path, g *model.Graph) {
 						occupied[nextRoom] = antID // Occupy next room
 					}
 					as.Pos++
-					moves = append(moves, fmt.Sprintf("L%d-%s", antID, nextRoom))
+					moves = append(moves, Move{Ant: antID, From: curRoom, To: nextRoom, Path: pi})
 					if nextRoom == endName {
 						finished++
 					}
@@ -154,7 +125,7 @@ func Run(ants int, paths []*model.Path, g *model.Graph) {
 			if len(p.Rooms) == 2 { // direct path start->end
 				antID := waitQueues[pi][0]
 				waitQueues[pi] = waitQueues[pi][1:]
-				moves = append(moves, fmt.Sprintf("L%d-%s", antID, endName))
+				moves = append(moves, Move{Ant: antID, From: startName, To: endName, Path: pi})
 				finished++
 				continue
 			}
@@ -166,7 +137,7 @@ func Run(ants int, paths []*model.Path, g *model.Graph) {
 				if first != startName && first != endName {
 					occupied[first] = antID
 				}
-				moves = append(moves, fmt.Sprintf("L%d-%s", antID, first))
+				moves = append(moves, Move{Ant: antID, From: startName, To: first, Path: pi})
 				if first == endName {
 					finished++
 				}
@@ -174,9 +145,97 @@ func Run(ants int, paths []*model.Path, g *model.Graph) {
 		}
 
 		if len(moves) > 0 {
-			fmt.Println(strings.Join(moves, " "))
+			sched.Turns = append(sched.Turns, moves)
 		} else {
 			break
 		}
 	}
+	return sched, nil
+}
+
+// TurnCount returns the number of turns Simulate needs to move ants along paths,
+// without simulating: the minimal T with Σ max(0, T - (L_i - 1)) ≥ ants.
+func TurnCount(ants int, paths []*model.Path) int {
+	if ants <= 0 || len(paths) == 0 {
+		return 0
+	}
+	lens := make([]int, len(paths))
+	for i, p := range paths {
+		lens[i] = p.Length
+	}
+	return TurnCountLengths(ants, lens)
+}
+
+// TurnCountLengths is TurnCount for paths of the given lengths, for callers
+// that score a path set before building it. It sorts lens in place.
+func TurnCountLengths(ants int, lens []int) int {
+	if ants <= 0 || len(lens) == 0 {
+		return 0
+	}
+	sort.Ints(lens)
+	return balance(ants, lens)
+}
+
+// Assign returns how many ants Simulate sends along each of paths, in the
+// order given: with T = TurnCount, path i takes T - (L_i - 1) ants, shortest
+// paths first (ties in the given order) until the ants run out.
+func Assign(ants int, paths []*model.Path) []int {
+	counts := make([]int, len(paths))
+	if ants <= 0 || len(paths) == 0 {
+		return counts
+	}
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return paths[order[a]].Length < paths[order[b]].Length })
+	lens := make([]int, len(paths))
+	for k, i := range order {
+		lens[k] = paths[i].Length
+	}
+	T := balance(ants, lens)
+
+	remain := ants
+	for _, i := range order {
+		base := paths[i].Length - 1
+		take := 0
+		if T > base {
+			take = T - base
+		}
+		if take > remain {
+			take = remain
+		}
+		counts[i] = take
+		remain -= take
+	}
+	for _, i := range order {
+		if remain == 0 {
+			break
+		}
+		counts[i]++
+		remain--
+	}
+	return counts
+}
+
+// balance finds the minimal T with Σ max(0, T - (L_i - 1)) ≥ ants.
+// lens must be sorted ascending.
+func balance(ants int, lens []int) int {
+	T := lens[0] - 1
+	if T < 0 {
+		T = 0
+	}
+	for {
+		sum := 0
+		for _, L := range lens {
+			base := L - 1
+			if T > base {
+				sum += T - base
+			}
+		}
+		if sum >= ants {
+			return T
+		}
+		T++
+	}
 }
@@ -1 +1,246 @@
-package scheduler
\ No newline at end of file
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"lem-in/internal/model"
+)
+
+func pathOf(g *model.Graph, names ...string) *model.Path {
+	p := &model.Path{Length: len(names) - 1}
+	for _, n := range names {
+		p.Rooms = append(p.Rooms, g.Rooms[n])
+	}
+	return p
+}
+
+// checkTurns fails if a move does not start where its ant stands, an
+// intermediate room holds two ants at the end of a turn, an ant moves twice in
+// a turn, or not every ant reaches End.
+func checkTurns(t *testing.T, g *model.Graph, ants int, s *Schedule) {
+	t.Helper()
+	where := map[int]string{}
+	for ti, moves := range s.Turns {
+		moved := map[int]bool{}
+		for _, mv := range moves {
+			if moved[mv.Ant] {
+				t.Fatalf("turn %d: ant %d moves twice", ti+1, mv.Ant)
+			}
+			moved[mv.Ant] = true
+			if from, ok := where[mv.Ant]; (ok && from != mv.From) || (!ok && mv.From != g.Start.Name) {
+				t.Fatalf("turn %d: ant %d moves from %s but stands in %q", ti+1, mv.Ant, mv.From, from)
+			}
+			where[mv.Ant] = mv.To
+		}
+		held := map[string]int{}
+		for id, room := range where {
+			if room == g.End.Name {
+				continue
+			}
+			if other, ok := held[room]; ok {
+				t.Fatalf("turn %d: ants %d and %d both in %s", ti+1, other, id, room)
+			}
+			held[room] = id
+		}
+	}
+	for id := 1; id <= ants; id++ {
+		if where[id] != g.End.Name {
+			t.Fatalf("ant %d ends in %q", id, where[id])
+		}
+	}
+}
+
+// Two tunnel-disjoint paths crossing in hub room h.
+func TestSimulateSharedCrossingPaths(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 4, 0)
+	for _, r := range []string{"a", "b", "h", "c", "d"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"s", "a"}, {"s", "b"}, {"a", "h"}, {"b", "h"}, {"h", "c"}, {"h", "d"}, {"c", "e"}, {"d", "e"}} {
+		g.AddLink(l[0], l[1])
+	}
+	paths := []*model.Path{pathOf(g, "s", "a", "h", "c", "e"), pathOf(g, "s", "b", "h", "d", "e")}
+
+	turns := SimulateShared(5, paths, g)
+	checkTurns(t, g, 5, turns)
+	// h lets one ant through per turn: ant k reaches e on turn k+3
+	if turns.Len() != 8 {
+		t.Errorf("got %d turns, want 8", turns.Len())
+	}
+}
+
+func TestTurnCountMatchesSimulate(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "c", "d"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"s", "a"}, {"a", "e"}, {"s", "b"}, {"b", "c"}, {"c", "d"}, {"d", "e"}, {"s", "e"}} {
+		g.AddLink(l[0], l[1])
+	}
+	for ants := 1; ants <= 20; ants++ {
+		paths := []*model.Path{pathOf(g, "s", "b", "c", "d", "e"), pathOf(g, "s", "a", "e"), pathOf(g, "s", "e")}
+		want := TurnCount(ants, paths)
+		turns := Simulate(ants, paths, g)
+		checkTurns(t, g, ants, turns)
+		if turns.Len() != want {
+			t.Errorf("%d ants: simulated %d turns, TurnCount says %d", ants, turns.Len(), want)
+		}
+		for _, moves := range turns.Turns {
+			for _, mv := range moves {
+				if p := paths[mv.Path]; !onPath(p, mv.From, mv.To) {
+					t.Fatalf("%d ants: move %+v is not a step of path %d", ants, mv, mv.Path)
+				}
+			}
+		}
+	}
+}
+
+// Two groups cross in room x, in opposite directions along tunnel x-y.
+func TestSimulateGroupsSharesRoomsOverTime(t *testing.T) {
+	g := model.NewGraph()
+	for _, r := range []string{"n", "s", "w", "e", "x", "y"} {
+		g.AddRoom(r, 0, 0)
+	}
+	for _, l := range [][2]string{{"n", "x"}, {"x", "y"}, {"y", "s"}, {"w", "y"}, {"x", "e"}} {
+		g.AddLink(l[0], l[1])
+	}
+	groups := []GroupPaths{
+		{Ants: 2, Paths: []*model.Path{pathOf(g, "n", "x", "y", "s")}},
+		{Ants: 2, Paths: []*model.Path{pathOf(g, "w", "y", "x", "e")}},
+	}
+	turns := SimulateGroups(groups)
+
+	ends := map[int]string{1: "s", 2: "s", 3: "e", 4: "e"}
+	where := map[int]string{}
+	for ti, moves := range turns.Turns {
+		crossed := map[string]bool{}
+		for _, mv := range moves {
+			key := mv.From + "|" + mv.To
+			if mv.From > mv.To {
+				key = mv.To + "|" + mv.From
+			}
+			if crossed[key] {
+				t.Fatalf("turn %d: tunnel %s crossed twice", ti+1, key)
+			}
+			crossed[key] = true
+			where[mv.Ant] = mv.To
+		}
+		held := map[string]int{}
+		for id, room := range where {
+			if room == ends[id] {
+				continue
+			}
+			if other, ok := held[room]; ok {
+				t.Fatalf("turn %d: ants %d and %d both in %s", ti+1, other, id, room)
+			}
+			held[room] = id
+		}
+	}
+	for id, end := range ends {
+		if where[id] != end {
+			t.Fatalf("ant %d ends in %q, want %s", id, where[id], end)
+		}
+	}
+	// all four ants must leave on different turns (one crossing of x-y per
+	// turn), and leaving on turns 1-4 always clashes in x or y: 7 is optimal
+	if turns.Len() != 7 {
+		t.Errorf("got %d turns, want 7", turns.Len())
+	}
+}
+
+// onPath reports whether from-to is a step along p.
+func onPath(p *model.Path, from, to string) bool {
+	for i := 1; i < len(p.Rooms); i++ {
+		if p.Rooms[i-1].Name == from && p.Rooms[i].Name == to {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteTextClassicFormat(t *testing.T) {
+	s := &Schedule{Turns: [][]Move{
+		{{Ant: 1, From: "s", To: "a"}, {Ant: 2, From: "s", To: "b", Path: 1}},
+		{{Ant: 1, From: "a", To: "e"}},
+	}}
+	var b strings.Builder
+	if err := s.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "L1-a L2-b\nL1-e\n"
+	if b.String() != want {
+		t.Errorf("WriteText wrote %q, want %q", b.String(), want)
+	}
+}
+
+// Stream must print exactly what Simulate schedules, for any ant count.
+func TestStreamMatchesSimulate(t *testing.T) {
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "c", "d", "f", "h"} {
+		g.AddRoom(r, 0, 0)
+	}
+	sets := [][][]string{
+		{{"s", "e"}},
+		{{"s", "a", "b", "c", "e"}},
+		{{"s", "b", "c", "d", "e"}, {"s", "a", "e"}, {"s", "e"}},
+		{{"s", "a", "b", "e"}, {"s", "c", "d", "e"}, {"s", "f", "h", "e"}},
+	}
+	for ants := 1; ants <= 30; ants++ {
+		for si, set := range sets {
+			mk := func() []*model.Path {
+				var paths []*model.Path
+				for _, names := range set {
+					paths = append(paths, pathOf(g, names...))
+				}
+				return paths
+			}
+			var want, got strings.Builder
+			Simulate(ants, mk(), g).WriteText(&want)
+			turns, err := Stream(&got, ants, mk())
+			if err != nil || got.String() != want.String() {
+				t.Fatalf("%d ants, set %d: Stream wrote (%v)\n%s\nSimulate wrote\n%s", ants, si, err, got.String(), want.String())
+			}
+			if want := TurnCount(ants, mk()); turns != want {
+				t.Errorf("%d ants, set %d: Stream reports %d turns, want %d", ants, si, turns, want)
+			}
+		}
+	}
+}
+
+// Ten million ants stream in constant memory.
+func TestStreamTenMillionAnts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("long")
+	}
+	g := model.NewGraph()
+	g.Start = g.AddRoom("s", 0, 0)
+	g.End = g.AddRoom("e", 0, 0)
+	for _, r := range []string{"a", "b", "c", "d", "f"} {
+		g.AddRoom(r, 0, 0)
+	}
+	paths := []*model.Path{pathOf(g, "s", "a", "b", "e"), pathOf(g, "s", "c", "e"), pathOf(g, "s", "d", "f", "e")}
+	const ants = 10_000_000
+	var w countWriter
+	turns, err := Stream(&w, ants, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := TurnCount(ants, paths); turns != want || w.lines != want {
+		t.Errorf("%d turns, %d lines written, want %d", turns, w.lines, want)
+	}
+}
+
+type countWriter struct{ lines int }
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.lines += bytes.Count(p, []byte{'\n'})
+	return len(p), nil
+}
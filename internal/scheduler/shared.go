@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"sort"
+
+	"lem-in/internal/model"
+)
+
+// SimulateShared schedules ants on paths that may share intermediate rooms.
+//
+// Simulate relies on the paths being room-disjoint; here two paths can cross, so
+// ants are sequenced with a reservation table instead:
+//  1. An ant never waits once it has left the start, so an ant leaving at turn s
+//     stands in room i of its path at the end of turn s+i-1.
+//  2. Ants are placed one by one (ID order). For every path we look for the
+//     earliest departure whose rooms are all free at those turns, and take the
+//     path/departure with the earliest arrival (shorter path on ties).
+//  3. One ant leaves per path per turn, as in Simulate, and a tunnel is crossed
+//     by one ant per turn.
+//
+// A room is reserved for the turn an ant ends in it; the next ant may enter on
+// the following turn, when the first one moves on (same as Simulate). Paths
+// are sorted by length in place, as in Simulate.
+func SimulateShared(ants int, paths []*model.Path, g *model.Graph) *Schedule {
+	if ants <= 0 || len(paths) == 0 {
+		return &Schedule{}
+	}
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].Length < paths[j].Length })
+
+	tab := newTable()
+	departed := make([]map[int]bool, len(paths)) // path -> turns an ant left on it
+	next := make([]int, len(paths))              // earliest departure worth trying
+	for i := range paths {
+		departed[i] = make(map[int]bool)
+		next[i] = 1
+	}
+
+	type placement struct{ path, depart int }
+	plan := make([]placement, ants)
+	turns := 0
+	for a := 0; a < ants; a++ {
+		best := placement{-1, 0}
+		bestArrival := 0
+		for pi, p := range paths {
+			s := next[pi]
+			for departed[pi][s] || !tab.fits(p, s) {
+				s++
+			}
+			next[pi] = s // reservations only grow, earlier slots stay blocked
+			if arr := s + p.Length - 1; best.path == -1 || arr < bestArrival {
+				best, bestArrival = placement{pi, s}, arr
+			}
+		}
+		plan[a] = best
+		departed[best.path][best.depart] = true
+		tab.reserve(paths[best.path], best.depart)
+		if bestArrival > turns {
+			turns = bestArrival
+		}
+	}
+
+	out := &Schedule{Turns: make([][]Move, turns)}
+	for a, pl := range plan {
+		out.place(a+1, pl.path, paths[pl.path], pl.depart)
+	}
+	return out
+}
+
+// place adds the moves of ant leaving along p (path index pi) at turn depart,
+// one tunnel per turn without waiting.
+func (s *Schedule) place(ant, pi int, p *model.Path, depart int) {
+	for i := 1; i < len(p.Rooms); i++ {
+		t := depart + i - 2 // turn index (0-based) of the move into room i
+		s.Turns[t] = append(s.Turns[t], Move{Ant: ant, From: p.Rooms[i-1].Name, To: p.Rooms[i].Name, Path: pi})
+	}
+}
+
+// table is the reservation table of SimulateShared and SimulateGroups: the
+// turns at whose end an intermediate room holds an ant, and the turns in
+// which a tunnel is crossed (at most once per turn, so ants never swap).
+type table struct {
+	rooms map[*model.Room]map[int]bool
+	links map[[2]*model.Room]map[int]bool
+}
+
+func newTable() *table {
+	return &table{rooms: map[*model.Room]map[int]bool{}, links: map[[2]*model.Room]map[int]bool{}}
+}
+
+// linkKey orders the two rooms so both directions share one entry.
+func linkKey(a, b *model.Room) [2]*model.Room {
+	if a.Name > b.Name {
+		a, b = b, a
+	}
+	return [2]*model.Room{a, b}
+}
+
+// fits reports whether an ant leaving on p at turn s finds every room and
+// tunnel free: it crosses tunnel i-1→i during turn s+i-1 and ends that turn
+// in room i.
+func (t *table) fits(p *model.Path, s int) bool {
+	for i := 1; i < len(p.Rooms); i++ {
+		if i < len(p.Rooms)-1 && t.rooms[p.Rooms[i]][s+i-1] {
+			return false
+		}
+		if t.links[linkKey(p.Rooms[i-1], p.Rooms[i])][s+i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// reserve books p's rooms and tunnels for an ant leaving at turn s.
+func (t *table) reserve(p *model.Path, s int) {
+	for i := 1; i < len(p.Rooms); i++ {
+		if i < len(p.Rooms)-1 {
+			r := p.Rooms[i]
+			if t.rooms[r] == nil {
+				t.rooms[r] = map[int]bool{}
+			}
+			t.rooms[r][s+i-1] = true
+		}
+		k := linkKey(p.Rooms[i-1], p.Rooms[i])
+		if t.links[k] == nil {
+			t.links[k] = map[int]bool{}
+		}
+		t.links[k][s+i-1] = true
+	}
+}
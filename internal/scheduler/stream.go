@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+
+	"lem-in/internal/model"
+)
+
+/*
+Stream writes the schedule Simulate would produce, in the classic text format,
+without simulating it and without keeping any per-ant state.
+
+On room-disjoint paths no ant ever waits: the ant ahead always moves on in the
+same turn, before the next one steps in. So with ants numbered path by path
+(as Simulate assigns them), the k-th ant of path i (k from 0) leaves Start on
+turn k+1 and enters room j of the path on turn k+j. The moves of turn t are
+therefore known from the path lengths and ant counts alone:
+
+ 1. for every path, in order, the ants already on it, furthest first:
+    k = max(0, t-L) .. min(count-1, t-2), entering room t-k;
+ 2. for every path, in order, the ant leaving Start: k = t-1, if any is left.
+
+That is the order Simulate emits them in, so the output is byte-for-byte the
+same. Memory is the paths plus a fixed buffer, O(total path length), however
+many ants there are; the lines go straight through a buffered writer. Paths
+are sorted by length in place, as in Simulate. Stream returns the number of
+turns written.
+*/
+func Stream(w io.Writer, ants int, paths []*model.Path) (int, error) {
+	if ants <= 0 || len(paths) == 0 {
+		return 0, nil
+	}
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].Length < paths[j].Length })
+	counts := Assign(ants, paths)
+	first := make([]int, len(paths)) // ID of each path's first ant, minus one
+	turns, id := 0, 0
+	for i, p := range paths {
+		first[i] = id
+		id += counts[i]
+		if counts[i] > 0 && counts[i]-1+p.Length > turns {
+			turns = counts[i] - 1 + p.Length
+		}
+	}
+
+	bw := bufio.NewWriterSize(w, 64<<10)
+	var tok []byte // one move, reused
+	for t := 1; t <= turns; t++ {
+		sep := false
+		emit := func(ant int, room string) {
+			tok = tok[:0]
+			if sep {
+				tok = append(tok, ' ')
+			}
+			tok = append(tok, 'L')
+			tok = strconv.AppendInt(tok, int64(ant), 10)
+			tok = append(tok, '-')
+			tok = append(tok, room...)
+			bw.Write(tok)
+			sep = true
+		}
+		for i, p := range paths {
+			for k := max(0, t-p.Length); k <= min(counts[i]-1, t-2); k++ {
+				emit(first[i]+k+1, p.Rooms[t-k].Name)
+			}
+		}
+		for i, p := range paths {
+			if t-1 < counts[i] {
+				emit(first[i]+t, p.Rooms[1].Name)
+			}
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return t - 1, err
+		}
+	}
+	return turns, bw.Flush()
+}
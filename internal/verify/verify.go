@@ -0,0 +1,202 @@
+// Package verify referees lem-in solutions. It replays the moves on the map
+// by itself, without the path finders or the scheduler, so it can judge any
+// implementation's output, ours included.
+package verify
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lem-in/internal/model"
+)
+
+// Violation is the first rule a solution breaks.
+type Violation struct {
+	Turn int // 1-based turn of the offending move, or the last turn for ants left behind
+	Ant  int // ant ID; 0 when the move is too malformed to name one
+	Msg  string
+}
+
+func (v *Violation) Error() string {
+	if v.Ant == 0 {
+		return fmt.Sprintf("turn %d: %s", v.Turn, v.Msg)
+	}
+	return fmt.Sprintf("turn %d, ant %d: %s", v.Turn, v.Ant, v.Msg)
+}
+
+/*
+Solution replays a solution for ants ants on g and returns its turn count.
+
+The solution is the echoed map followed by one line of "L<ant>-<room>" moves
+per turn. Lines before the first move line (the echo and the blank line after
+it) are skipped; after it every non-empty line must be a turn. The rules are:
+
+  - each move follows a tunnel from the room the ant stands in;
+  - an ant moves at most once per turn, never back into Start and never
+    again once it reached End;
+  - at the end of a turn no room other than Start and End holds two ants;
+  - after the last turn every ant is in End.
+
+Moves within a turn are simultaneous, so an ant may enter a room another ant
+leaves in the same turn. The first broken rule is returned as a *Violation:
+within a turn, the move-by-move rules are checked in line order before room
+occupancy. Read errors are returned as they are.
+*/
+func Solution(g *model.Graph, ants int, r io.Reader) (int, error) {
+	return Groups(g, []model.Group{{Ants: ants, Start: g.Start, End: g.End}}, r)
+}
+
+/*
+Groups replays a solution for a map with several colonies (##group lines),
+given in the order the parser's AntGroups lists them. Ants are numbered group
+by group, so an ant's ID range names its group, and Solution's rules hold per
+group: the ant leaves its own Start, never returns to it and must end in its
+own End. Every group's Start and End may hold any number of ants; only the
+other rooms are limited to one.
+*/
+func Groups(g *model.Graph, groups []model.Group, r io.Reader) (int, error) {
+	f := &referee{g: g, groups: groups, terminal: map[*model.Room]bool{}}
+	ants := 0
+	for _, gr := range groups {
+		ants += gr.Ants
+		f.last = append(f.last, ants)
+		f.terminal[gr.Start] = true
+		f.terminal[gr.End] = true
+	}
+	where := make([]*model.Room, ants+1) // ant -> room, nil while in its Start
+	holder := map[*model.Room]int{}      // intermediate room -> ant in it
+	arrived := 0
+	turn := 0
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return turn, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case turn == 0 && !strings.HasPrefix(line, "L"):
+			// still in the echoed map
+		case line == "":
+			// tolerate blank lines between and after turns
+		default:
+			turn++
+			n, v := f.replayTurn(turn, line, where, holder)
+			if v != nil {
+				return turn, v
+			}
+			arrived += n
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if arrived < ants {
+		for ant := 1; ant <= ants; ant++ {
+			gr := f.group(ant)
+			if where[ant] != gr.End {
+				room := gr.Start.Name
+				if where[ant] != nil {
+					room = where[ant].Name
+				}
+				return turn, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("still in %s after the last turn, never reaches %s", room, gr.End.Name)}
+			}
+		}
+	}
+	return turn, nil
+}
+
+// referee holds the map being replayed and its colonies.
+type referee struct {
+	g        *model.Graph
+	groups   []model.Group
+	last     []int                // last[i] is the highest ant ID of groups[i]
+	terminal map[*model.Room]bool // every group's Start and End
+}
+
+// group returns the colony ant 1..last[len(last)-1] belongs to.
+func (f *referee) group(ant int) *model.Group {
+	return &f.groups[sort.SearchInts(f.last, ant)]
+}
+
+// replayTurn applies one turn line and returns how many ants reached their End.
+func (f *referee) replayTurn(turn int, line string, where []*model.Room, holder map[*model.Room]int) (int, *Violation) {
+	type step struct {
+		ant      int
+		from, to *model.Room
+		home     *model.Group
+	}
+	var steps []step
+	moved := map[int]bool{}
+	for _, tok := range strings.Fields(line) {
+		dash := strings.IndexByte(tok, '-')
+		if !strings.HasPrefix(tok, "L") || dash < 0 {
+			return 0, &Violation{Turn: turn, Msg: fmt.Sprintf("malformed move %q", tok)}
+		}
+		ant, err := strconv.Atoi(tok[1:dash])
+		if err != nil {
+			return 0, &Violation{Turn: turn, Msg: fmt.Sprintf("malformed move %q", tok)}
+		}
+		if ant < 1 || ant >= len(where) {
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("no such ant (the map has %d)", len(where)-1)}
+		}
+		to, ok := f.g.Rooms[tok[dash+1:]]
+		if !ok {
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("moves to unknown room %q", tok[dash+1:])}
+		}
+		home := f.group(ant)
+		from := where[ant]
+		if from == nil {
+			from = home.Start
+		}
+		switch {
+		case moved[ant]:
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: "moves twice in one turn"}
+		case from == home.End:
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("moves again after reaching %s", home.End.Name)}
+		case to == home.Start:
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("returns to %s", home.Start.Name)}
+		case !linked(from, to):
+			return 0, &Violation{Turn: turn, Ant: ant, Msg: fmt.Sprintf("no tunnel %s-%s", from.Name, to.Name)}
+		}
+		moved[ant] = true
+		steps = append(steps, step{ant, from, to, home})
+	}
+
+	// moves are simultaneous: vacate first, then occupy
+	for _, s := range steps {
+		if holder[s.from] == s.ant {
+			delete(holder, s.from)
+		}
+		where[s.ant] = s.to
+	}
+	arrived := 0
+	for _, s := range steps {
+		if s.to == s.home.End {
+			arrived++
+		}
+		if f.terminal[s.to] {
+			continue
+		}
+		if other, ok := holder[s.to]; ok {
+			return 0, &Violation{Turn: turn, Ant: s.ant, Msg: fmt.Sprintf("enters %s, which ant %d holds", s.to.Name, other)}
+		}
+		holder[s.to] = s.ant
+	}
+	return arrived, nil
+}
+
+func linked(a, b *model.Room) bool {
+	for _, l := range a.Links {
+		if l == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+const farm = `2
+##start
+s 0 0
+##end
+e 3 0
+a 1 0
+b 2 0
+c 1 1
+s-a
+a-b
+b-e
+s-c
+c-e
+`
+
+func TestSolutionRules(t *testing.T) {
+	res, err := parser.Parse(bufio.NewScanner(strings.NewReader(farm)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name, moves string
+		turns       int
+		want        string // violation text, "" when valid
+	}{
+		{"valid", "L1-a L2-c\nL1-b L2-e\nL1-e\n", 3, ""},
+		{"one path", "L1-a\nL1-b L2-a\nL1-e L2-b\nL2-e\n", 4, ""},
+		{"no tunnel", "L1-b\n", 1, "turn 1, ant 1: no tunnel s-b"},
+		{"shared room", "L1-a\nL1-b L2-a\nL2-b\n", 3, "turn 3, ant 2: enters b, which ant 1 holds"},
+		{"twice", "L1-a L1-b\n", 1, "turn 1, ant 1: moves twice in one turn"},
+		{"back to start", "L1-a L2-c\nL1-s\n", 2, "turn 2, ant 1: returns to s"},
+		{"after end", "L1-c\nL1-e\nL1-c\n", 3, "turn 3, ant 1: moves again after reaching e"},
+		{"left behind", "L1-c\nL1-e\n", 2, "turn 2, ant 2: still in s after the last turn, never reaches e"},
+		{"no such ant", "L3-a\n", 1, "turn 1, ant 3: no such ant (the map has 2)"},
+		{"unknown room", "L1-x\n", 1, `turn 1, ant 1: moves to unknown room "x"`},
+		{"malformed", "L1-a\nbogus\n", 2, `turn 2: malformed move "bogus"`},
+	}
+	for _, tc := range cases {
+		turns, err := Solution(res.Graph, res.Ants, strings.NewReader(farm+"\n"+tc.moves))
+		var v *Violation
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.want != "" && (!errors.As(err, &v) || v.Error() != tc.want):
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.want)
+		case turns != tc.turns:
+			t.Errorf("%s: %d turns, want %d", tc.name, turns, tc.turns)
+		}
+	}
+}
+
+const groupFarm = `3
+##group east 2 w e2
+##start
+s 0 0
+##end
+e 2 0
+w 0 1
+e2 2 1
+m 1 0
+s-m
+m-e
+w-m
+m-e2
+`
+
+// Each group is held to its own start and end; terminals hold any number.
+func TestGroupRules(t *testing.T) {
+	res, err := parser.Parse(bufio.NewScanner(strings.NewReader(groupFarm)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name, moves string
+		want        string // violation text, "" when valid
+	}{
+		{"valid", "L1-m\nL1-e L4-m\nL4-e2 L2-m\nL2-e L5-m\nL5-e2 L3-m\nL3-e\n", ""},
+		{"shared room", "L1-m L4-m\n", "turn 1, ant 4: enters m, which ant 1 holds"},
+		{"through a foreign start", "L4-m\nL4-s L1-m\nL1-e\nL2-m\nL2-e L4-m\nL4-e2 L3-m\nL3-e L5-m\nL5-e2\n", ""},
+		{"foreign end", "L1-m\nL1-e2\n", "turn 2, ant 1: still in e2 after the last turn, never reaches e"},
+		{"own start", "L4-m\nL4-w\n", "turn 2, ant 4: returns to w"},
+		{"no such ant", "L6-m\n", "turn 1, ant 6: no such ant (the map has 5)"},
+	}
+	for _, tc := range cases {
+		_, err := Groups(res.Graph, res.AntGroups(), strings.NewReader(groupFarm+"\n"+tc.moves))
+		var v *Violation
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.want != "" && (!errors.As(err, &v) || v.Error() != tc.want):
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// Every example map's schedule must pass the referee with its own turn count.
+func TestOwnSchedulesPass(t *testing.T) {
+	files, _ := filepath.Glob("../../example*.txt")
+	if len(files) == 0 {
+		t.Fatal("no example maps")
+	}
+	for _, file := range files {
+		res, err := parser.ParseFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, algo := range []string{path.AlgoEdmondsKarp, path.AlgoExact} {
+			sel, err := path.Find(context.Background(), algo, res.Graph, path.Options{Ants: res.Ants})
+			if err != nil {
+				t.Fatalf("%s (%s): %v", file, algo, err)
+			}
+			sched, err := sel.Schedule(res.Ants, res.Graph)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			out.WriteString(strings.Join(res.OriginalLines, "\n") + "\n\n")
+			sched.WriteText(&out)
+			turns, err := Solution(res.Graph, res.Ants, &out)
+			if err != nil || turns != sched.Len() {
+				t.Errorf("%s (%s): %d turns, %v; schedule has %d", file, algo, turns, err, sched.Len())
+			}
+		}
+	}
+}
@@ -1,20 +1,69 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"lem-in/internal/parser"
 	"lem-in/internal/path"
 	"lem-in/internal/scheduler"
 )
 
+const usage = `Usage:
+  go run . [solve] [-v] [-stats] [-explain] [-trace] [-timeout d] [-algo name] [-ties policy] [-seed n] [-mode room|edge] <input-file>
+  go run . paths [-k n] [-timeout d] <input-file>
+  go run . check-optimal [-algo name] <input-file>
+  go run . resilience [-algo name] <input-file>
+  go run . verify <input-file> <solution-file|->`
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run . <input-file>")
+	args := os.Args[1:]
+	cmd := "solve"
+	if len(args) > 0 && (args[0] == "solve" || args[0] == "paths" || args[0] == "check-optimal" || args[0] == "resilience" || args[0] == "verify") {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "paths":
+		runPaths(args)
+	case "check-optimal":
+		runCheckOptimal(args)
+	case "resilience":
+		runResilience(args)
+	case "verify":
+		runVerify(args)
+	default:
+		runSolve(args)
+	}
+}
+
+// runSolve is the classic lem-in run: echo the map, then print the moves.
+func runSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "report every evaluated path set on stderr")
+	algo := fs.String("algo", path.AlgoEdmondsKarp, "path finder: "+strings.Join(path.Names(), ", "))
+	timeout := fs.Duration("timeout", 0, "time budget for path finding; the best paths found so far are used (0 = no limit)")
+	explain := fs.Bool("explain", false, "report the bottleneck rooms and tunnels (min cut) on stderr")
+	stats := fs.Bool("stats", false, "report the turn count, its lower bounds and the gap on stderr")
+	trace := fs.Bool("trace", false, "print every augmenting path of the Edmonds–Karp max flow on stderr (a reference, whatever -algo)")
+	ties := fs.String("ties", path.TieName, "tie-breaking policy: "+strings.Join(path.TiePolicies(), ", "))
+	seed := fs.Int64("seed", 1, "seed for -ties random")
+	mode := fs.String("mode", "room", "room: room-disjoint paths; edge: tunnel-disjoint paths sharing rooms over time")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fmt.Println(usage)
 		os.Exit(0)
 	}
-	res, err := parser.ParseFile(os.Args[1])
+	if *mode != "room" && *mode != "edge" {
+		fmt.Printf("unknown mode %q (want room or edge)\n", *mode)
+		os.Exit(0)
+	}
+	res, err := parser.ParseFile(fs.Arg(0))
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(0)
@@ -26,14 +75,142 @@ func main() {
 	}
 	fmt.Println()
 
-	// find multiple disjoint shortest paths (no explicit maximum)
-	paths := path.MultiPath(res.Graph, 0) // 0 => unlimited until none found
-
-	if len(paths) == 0 {
+	// find disjoint shortest paths, keeping the flow level that needs the fewest turns
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	if msg := constraintConflict(res, *mode); msg != "" {
+		fmt.Println("ERROR:", msg)
+		os.Exit(0)
+	}
+	if len(res.Groups) > 0 {
+		runGroups(ctx, res, *algo)
+		return
+	}
+	sel, err := path.Find(ctx, *algo, res.Graph, path.Options{
+		Ants:  res.Ants, // MaxPaths 0 => unlimited
+		Ties:  path.TieBreak{Policy: *ties, Seed: *seed},
+		Avoid: res.Avoid,
+		Via:   res.Via,
+	})
+	if errors.Is(err, path.ErrNoPath) {
 		fmt.Println("ERROR: invalid data format, no path found")
 		os.Exit(0)
 	}
+	if errors.Is(err, path.ErrUnsatisfiable) {
+		fmt.Println("ERROR:", err)
+		os.Exit(0)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("ERROR: no path found within %v\n", *timeout)
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+	if sel.Partial {
+		fmt.Fprintf(os.Stderr, "path finding stopped after %v: using the best paths found so far\n", *timeout)
+	}
+	if sel.Portfolio != nil {
+		writePortfolio(os.Stderr, sel.Portfolio)
+	}
+	if *explain {
+		writeExplain(os.Stderr, res, sel)
+	}
+	if *trace {
+		// a reference: always Edmonds–Karp's flow, numbered by -ties
+		ties := path.TieBreak{Policy: *ties, Seed: *seed}
+		tr, err := path.TraceFlow(ctx, res.Graph, path.Options{Ties: ties})
+		note := ""
+		if sel.Algo != path.AlgoEdmondsKarp {
+			note = "; " + sel.Algo + " chose the paths"
+		}
+		switch {
+		case err != nil && ctx.Err() == nil:
+			fmt.Fprintln(os.Stderr, "trace:", err)
+		default:
+			fmt.Fprintf(os.Stderr, "max-flow augmentations (Edmonds–Karp reference trace, %s%s):\n", ties, note)
+			tr.WriteText(os.Stderr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "trace stopped after %v: the flow above is not maximal\n", *timeout)
+			}
+		}
+	}
+	if *mode == "edge" {
+		runEdgeMode(res, sel, *stats)
+		return
+	}
+	if *stats {
+		writeStats(os.Stderr, res, sel.Turns)
+	}
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "algo %s (%s), %d nodes expanded\n", sel.Algo, sel.Settings, sel.Expanded)
+		for _, c := range sel.Candidates {
+			mark := ""
+			if c.Flow == sel.Flow {
+				mark = " (chosen)"
+			}
+			fmt.Fprintf(os.Stderr, "flow %d: %d turns%s\n", c.Flow, c.Turns, mark)
+		}
+	}
+
+	// stream the moves; they match the schedule the visualizer animates
+	sel.WriteSchedule(os.Stdout, res.Ants)
+}
 
-	// Run the scheduler that prints ant moves
-	scheduler.Run(res.Ants, paths, res.Graph)
+// constraintConflict explains why the map's ##avoid and ##via rooms cannot be
+// honoured in this run, or returns "". Group routing and the edge-disjoint
+// mode do not apply them, and ignoring them silently could route ants
+// through an avoided room.
+func constraintConflict(res *parser.Result, mode string) string {
+	if len(res.Avoid) == 0 && len(res.Via) == 0 {
+		return ""
+	}
+	switch {
+	case len(res.Groups) > 0:
+		return "##avoid and ##via do not apply to maps with ##group"
+	case mode == "edge":
+		return "##avoid and ##via do not apply to -mode edge"
+	}
+	return ""
+}
+
+// runEdgeMode routes ants on tunnel-disjoint paths that may share rooms,
+// and reports on stderr whether that beats the room-disjoint answer.
+func runEdgeMode(res *parser.Result, roomSel *path.Selection, stats bool) {
+	sel := path.BestEdgeDisjoint(res.Graph, res.Ants, 0)
+	verdict := "not better than"
+	if sel.Turns < roomSel.Turns {
+		verdict = "better than"
+	}
+	fmt.Fprintf(os.Stderr, "edge-disjoint: %d paths, %d turns; room-disjoint (%s): %d paths, %d turns; edge-disjoint is %s room-disjoint\n",
+		sel.Flow, sel.Turns, roomSel.Algo, roomSel.Flow, roomSel.Turns, verdict)
+	if stats {
+		writeStats(os.Stderr, res, sel.Turns)
+	}
+	scheduler.SimulateShared(res.Ants, sel.Paths, res.Graph).WriteText(os.Stdout)
+}
+
+// writePortfolio reports which portfolio configuration won and how long each
+// one ran.
+func writePortfolio(w io.Writer, rep *path.PortfolioReport) {
+	fmt.Fprintf(w, "portfolio winner: %v (lower bound %d turns)\n", rep.Winner, rep.LowerBound)
+	for _, r := range rep.Runs {
+		status := fmt.Sprintf("%d turns", r.Turns)
+		switch {
+		case r.Err != nil:
+			status = "error: " + r.Err.Error()
+		case r.Optimal:
+			status += ", optimal"
+		case r.Cancelled && r.Turns == 0:
+			status = "cancelled"
+		case r.Cancelled:
+			status += ", cancelled"
+		}
+		fmt.Fprintf(w, "  %-32v %10v  %s\n", r.Config, r.Elapsed.Round(time.Microsecond), status)
+	}
 }
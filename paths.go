@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+// runPaths lists the k shortest simple Start→End paths (Yen's algorithm).
+func runPaths(args []string) {
+	fs := flag.NewFlagSet("paths", flag.ExitOnError)
+	k := fs.Int("k", 10, "number of paths to list")
+	timeout := fs.Duration("timeout", 0, "stop after this long and print the paths found so far (0 = no limit)")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fmt.Println(usage)
+		os.Exit(0)
+	}
+	res, err := parser.ParseFile(fs.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+
+	var deadline time.Time
+	if *timeout > 0 {
+		deadline = time.Now().Add(*timeout)
+	}
+	paths, complete := path.KShortestUntil(res.Graph, *k, deadline)
+	if len(paths) == 0 {
+		fmt.Println("ERROR: invalid data format, no path found")
+		os.Exit(0)
+	}
+	for i, p := range paths {
+		fmt.Printf("%d (%d): %s\n", i+1, p.Length, roomNames(p))
+	}
+	if !complete {
+		fmt.Fprintf(os.Stderr, "stopped after %v with %d of %d paths\n", *timeout, len(paths), *k)
+	}
+}
@@ -0,0 +1,23 @@
+// Package lemin is the importable API of the lem-in solver.
+//
+// It wraps the internal parser, path finder and scheduler behind a small set
+// of plain value types so other Go modules can solve ant farms without
+// reaching into internal/ packages.
+//
+// # Compatibility
+//
+// The package follows semantic versioning, tracked by the Version constant.
+// Within a major version:
+//   - exported identifiers are never removed or renamed;
+//   - function signatures never change;
+//   - fields may be added to Options, Solution and Stats, so construct them
+//     with field names rather than positional literals;
+//   - the zero Options value keeps selecting the default behaviour;
+//   - for the same input and Options the moves in a Solution are identical
+//     to the moves printed by the lem-in command.
+//
+// Everything under internal/ may change at any time.
+package lemin
+
+// Version is the semantic version of the lemin API.
+const Version = "1.0.0"
@@ -0,0 +1,69 @@
+package lemin_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"lem-in/pkg/lemin"
+)
+
+func ExampleSolve() {
+	farm := `3
+##start
+start 0 0
+a 1 0
+##end
+end 2 0
+start-a
+a-end
+`
+	sol, err := lemin.Solve(context.Background(), strings.NewReader(farm), lemin.Options{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("paths:", sol.Stats.Paths, "turns:", sol.Stats.Turns)
+	sol.WriteMoves(os.Stdout)
+	// Output:
+	// paths: 1 turns: 4
+	// L1-a
+	// L1-end L2-a
+	// L2-end L3-a
+	// L3-end
+}
+
+func ExampleSolveGraph() {
+	g := lemin.Graph{
+		Start: "s",
+		End:   "e",
+		Rooms: []lemin.Room{
+			{Name: "s", Links: []string{"a", "b"}},
+			{Name: "a", Links: []string{"e"}},
+			{Name: "b", Links: []string{"e"}},
+			{Name: "e"},
+		},
+	}
+	sol, err := lemin.SolveGraph(context.Background(), g, 4, lemin.Options{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, p := range sol.Paths {
+		fmt.Println(strings.Join(p.Rooms, "->"))
+	}
+	fmt.Println("turns:", sol.Stats.Turns)
+	// Output:
+	// s->a->e
+	// s->b->e
+	// turns: 3
+}
+
+func ExampleSolveGraph_invalid() {
+	g := lemin.Graph{Start: "s", End: "s", Rooms: []lemin.Room{{Name: "s"}}}
+	_, err := lemin.SolveGraph(context.Background(), g, 1, lemin.Options{})
+	fmt.Println(err)
+	// Output:
+	// lemin: start and end are the same room
+}
@@ -0,0 +1,254 @@
+package lemin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"lem-in/internal/model"
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+	"lem-in/internal/scheduler"
+)
+
+// ErrNoPath is returned when End cannot be reached from Start.
+var ErrNoPath = errors.New("lemin: no path from start to end")
+
+// ErrInvalidMap is returned (wrapped) by Solve when the input is not a valid
+// map; the message says what is wrong, in the lem-in command's words.
+var ErrInvalidMap = errors.New("lemin: invalid data format")
+
+// ErrUnsatisfiable is returned (wrapped) when no path set meets the Avoid and
+// Via options.
+var ErrUnsatisfiable = path.ErrUnsatisfiable
+
+// ErrGroups is returned by Solve for maps with ##group lines. Their colonies
+// are routed and scheduled jointly, which Solution cannot describe; the lem-in
+// command solves them.
+var ErrGroups = errors.New("lemin: ##group maps are not supported")
+
+// Options tunes Solve. The zero value is the default behaviour.
+type Options struct {
+	MaxPaths int      // upper bound on the number of paths used; 0 means no limit
+	Algo     string   // path finder name, see Algorithms; empty means "edmonds-karp"
+	TieBreak string   // tie-breaking policy, see TiePolicies; empty means "name"
+	Seed     int64    // seed for the "random" tie-breaking policy
+	Avoid    []string // rooms no path may enter (added to the map's ##avoid rooms)
+	Via      []string // rooms some path must pass (added to the map's ##via rooms)
+}
+
+// Algorithms lists the path finder names accepted in Options.Algo.
+func Algorithms() []string { return path.Names() }
+
+// TiePolicies lists the tie-breaking policies accepted in Options.TieBreak.
+// Each policy (and seed) gives the same solution on every run.
+func TiePolicies() []string { return path.TiePolicies() }
+
+// Room is a room of the farm.
+type Room struct {
+	Name  string
+	X, Y  int
+	Links []string // neighbour names, sorted
+}
+
+// Graph is the farm as a plain value.
+type Graph struct {
+	Rooms []Room // sorted by name
+	Start string
+	End   string
+}
+
+// Path is one route used by the ants, Start and End included.
+type Path struct {
+	Rooms  []string
+	Length int // number of links
+}
+
+// Move is a single ant stepping into Room.
+type Move struct {
+	Ant  int
+	Room string
+}
+
+// String renders the move in the classic "L<ant>-<room>" form.
+func (m Move) String() string { return fmt.Sprintf("L%d-%s", m.Ant, m.Room) }
+
+// Stats summarises a solution.
+type Stats struct {
+	Ants  int
+	Rooms int
+	Links int
+	Paths int
+	Turns int
+	Moves int
+}
+
+// FlowLevel is one candidate path set compared while choosing Paths.
+type FlowLevel struct {
+	Paths int // number of disjoint paths
+	Turns int // turns needed with them
+}
+
+// Solution is the result of Solve.
+type Solution struct {
+	Ants       int
+	Graph      Graph
+	Paths      []Path
+	Turns      [][]Move // Turns[i] holds the moves of turn i+1
+	Stats      Stats
+	FlowLevels []FlowLevel // every evaluated path set, by increasing flow
+	Partial    bool        // ctx ended during path finding; Paths is the best set found so far
+	Algo       string      // path finder used
+	Settings   string      // its settings, enough to reproduce the run
+}
+
+// Solve parses a map from r, finds the paths and schedules the ants.
+func Solve(ctx context.Context, r io.Reader, opts Options) (*Solution, error) {
+	res, err := parser.Parse(bufio.NewScanner(r))
+	if errors.Is(err, parser.ErrInvalid) {
+		detail := strings.TrimPrefix(err.Error(), parser.ErrInvalid.Error())
+		return nil, fmt.Errorf("%w%s", ErrInvalidMap, detail)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lemin: %w", err)
+	}
+	if len(res.Groups) > 0 {
+		return nil, ErrGroups
+	}
+	// fresh slices: neither the parser's nor the caller's arrays are written
+	opts.Avoid = slices.Concat(res.Avoid, opts.Avoid)
+	opts.Via = slices.Concat(res.Via, opts.Via)
+	return solve(ctx, res.Ants, res.Graph, opts)
+}
+
+// SolveGraph solves a farm built in code. The graph is checked with the same
+// rules the parser applies: room names are unique, and every link joins two
+// different, existing rooms and is listed at most once per room. A link may
+// be listed by both of its rooms (as Solution.Graph does); it is one tunnel.
+func SolveGraph(ctx context.Context, g Graph, ants int, opts Options) (*Solution, error) {
+	if ants <= 0 {
+		return nil, fmt.Errorf("lemin: invalid number of ants %d", ants)
+	}
+	mg := model.NewGraph()
+	for _, r := range g.Rooms {
+		if _, dup := mg.Rooms[r.Name]; dup {
+			return nil, fmt.Errorf("lemin: duplicate room %s", r.Name)
+		}
+		mg.AddRoom(r.Name, r.X, r.Y)
+	}
+	for _, r := range g.Rooms {
+		listed := map[string]bool{}
+		for _, l := range r.Links {
+			_, ok := mg.Rooms[l]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("lemin: link %s-%s: unknown room %s", r.Name, l, l)
+			case l == r.Name:
+				return nil, fmt.Errorf("lemin: link %s-%s: a room cannot link to itself", r.Name, l)
+			case listed[l]:
+				return nil, fmt.Errorf("lemin: link %s-%s: duplicate link", r.Name, l)
+			}
+			listed[l] = true
+			mg.AddLink(r.Name, l) // undirected: the other room may list it too
+		}
+	}
+	mg.Start = mg.Rooms[g.Start]
+	mg.End = mg.Rooms[g.End]
+	if issues := mg.Validate(); len(issues) > 0 {
+		return nil, fmt.Errorf("lemin: %s", issues[0].Message)
+	}
+	return solve(ctx, ants, mg, opts)
+}
+
+func solve(ctx context.Context, ants int, g *model.Graph, opts Options) (*Solution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sel, err := path.Find(ctx, opts.Algo, g, path.Options{
+		Ants:     ants,
+		MaxPaths: opts.MaxPaths,
+		Ties:     path.TieBreak{Policy: opts.TieBreak, Seed: opts.Seed},
+		Avoid:    opts.Avoid,
+		Via:      opts.Via,
+	})
+	if errors.Is(err, path.ErrNoPath) {
+		return nil, ErrNoPath
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lemin: %w", err)
+	}
+	paths := sel.Paths
+	// A partial path set means ctx is already done; scheduling is linear in
+	// the output, so finish it rather than throw the partial answer away.
+	var sched *scheduler.Schedule
+	if sel.Partial {
+		sched, err = sel.Schedule(ants, g)
+	} else {
+		sched, err = sel.ScheduleContext(ctx, ants, g)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sol := &Solution{Ants: ants, Graph: exportGraph(g), Partial: sel.Partial, Algo: sel.Algo, Settings: sel.Settings}
+	for _, c := range sel.Candidates {
+		sol.FlowLevels = append(sol.FlowLevels, FlowLevel{Paths: c.Flow, Turns: c.Turns})
+	}
+	for _, p := range paths {
+		names := make([]string, len(p.Rooms))
+		for i, r := range p.Rooms {
+			names[i] = r.Name
+		}
+		sol.Paths = append(sol.Paths, Path{Rooms: names, Length: p.Length})
+	}
+	for _, t := range sched.Turns {
+		moves := make([]Move, len(t))
+		for i, m := range t {
+			moves[i] = Move{Ant: m.Ant, Room: m.To}
+		}
+		sol.Turns = append(sol.Turns, moves)
+		sol.Stats.Moves += len(moves)
+	}
+	sol.Stats.Ants = ants
+	sol.Stats.Rooms = len(sol.Graph.Rooms)
+	for _, r := range sol.Graph.Rooms {
+		sol.Stats.Links += len(r.Links)
+	}
+	sol.Stats.Links /= 2
+	sol.Stats.Paths = len(sol.Paths)
+	sol.Stats.Turns = len(sol.Turns)
+	return sol, nil
+}
+
+// WriteMoves writes the turns in the classic text format, one line per turn.
+func (s *Solution) WriteMoves(w io.Writer) error {
+	for _, t := range s.Turns {
+		parts := make([]string, len(t))
+		for i, m := range t {
+			parts[i] = m.String()
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportGraph(g *model.Graph) Graph {
+	out := Graph{Start: g.Start.Name, End: g.End.Name}
+	for _, r := range g.Rooms {
+		links := make([]string, len(r.Links))
+		for i, l := range r.Links {
+			links[i] = l.Name
+		}
+		sort.Strings(links)
+		out.Rooms = append(out.Rooms, Room{Name: r.Name, X: r.X, Y: r.Y, Links: links})
+	}
+	sort.Slice(out.Rooms, func(i, j int) bool { return out.Rooms[i].Name < out.Rooms[j].Name })
+	return out
+}
@@ -0,0 +1,68 @@
+package lemin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSolveGraphRejectsWhatTheParserRejects(t *testing.T) {
+	room := func(name string, links ...string) Room { return Room{Name: name, Links: links} }
+	cases := []struct {
+		name  string
+		rooms []Room
+		want  string // error text, "" when valid
+	}{
+		{"valid", []Room{room("s", "a"), room("a", "e"), room("e")}, ""},
+		{"listed by both ends", []Room{room("s", "a"), room("a", "s", "e"), room("e", "a")}, ""},
+		{"duplicate room", []Room{room("s", "a"), room("a", "e"), room("a"), room("e")}, "lemin: duplicate room a"},
+		{"self-link", []Room{room("s", "a"), room("a", "a", "e"), room("e")}, "lemin: link a-a: a room cannot link to itself"},
+		{"unknown room", []Room{room("s", "x"), room("e")}, "lemin: link s-x: unknown room x"},
+		{"duplicate link", []Room{room("s", "a", "a"), room("a", "e"), room("e")}, "lemin: link s-a: duplicate link"},
+	}
+	for _, tc := range cases {
+		_, err := SolveGraph(context.Background(), Graph{Start: "s", End: "e", Rooms: tc.rooms}, 2, Options{})
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.want != "" && (err == nil || err.Error() != tc.want):
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// Solve adds the caller's constraints to the map's without writing to either.
+func TestSolveDoesNotAliasConstraints(t *testing.T) {
+	const farm = "2\n##start\ns 0 0\n##end\ne 3 0\na 1 0\nb 1 1\nc 1 2\ns-a\na-e\ns-b\nb-e\ns-c\nc-e\n##avoid a\n"
+	avoid := make([]string, 1, 4)
+	avoid[0] = "b"
+	sol, err := Solve(context.Background(), strings.NewReader(farm), Options{Avoid: avoid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full := avoid[:cap(avoid)]; full[1] != "" {
+		t.Errorf("Solve wrote %q past the caller's Avoid", full[1:])
+	}
+	for _, p := range sol.Paths {
+		if p.Rooms[1] != "c" {
+			t.Errorf("path %v enters an avoided room", p.Rooms)
+		}
+	}
+}
+
+// Group maps are refused rather than solved for the main colony alone.
+func TestSolveRefusesGroups(t *testing.T) {
+	const farm = "1\n##group east 1 w x\n##start\ns 0 0\n##end\ne 2 0\nw 0 1\nx 2 1\ns-e\nw-x\n"
+	if _, err := Solve(context.Background(), strings.NewReader(farm), Options{}); !errors.Is(err, ErrGroups) {
+		t.Errorf("got %v, want ErrGroups", err)
+	}
+}
+
+// Parse errors come back under ErrInvalidMap, with the parser's reason.
+func TestSolveWrapsParseErrors(t *testing.T) {
+	_, err := Solve(context.Background(), strings.NewReader("2\n##start\ns 0 0\n\n##end\ne 1 0\ns-e\n"), Options{})
+	if !errors.Is(err, ErrInvalidMap) || err.Error() != "lemin: invalid data format, empty line" {
+		t.Errorf("got %v, want ErrInvalidMap", err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"lem-in/internal/model"
+	"lem-in/internal/parser"
+	"lem-in/internal/path"
+)
+
+// runResilience re-solves the map once per room of the chosen paths, with
+// that room removed and the same finder and constraints, and lists the
+// outcomes worst first.
+func runResilience(args []string) {
+	fs := flag.NewFlagSet("resilience", flag.ExitOnError)
+	algo := fs.String("algo", path.AlgoEdmondsKarp, "path finder for the intact map: "+strings.Join(path.Names(), ", "))
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fmt.Println(usage)
+		os.Exit(0)
+	}
+	res, err := parser.ParseFile(fs.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+
+	ctx := context.Background()
+	opts := path.Options{Ants: res.Ants, Avoid: res.Avoid, Via: res.Via}
+	sel, err := path.Find(ctx, *algo, res.Graph, opts)
+	if errors.Is(err, path.ErrNoPath) {
+		fmt.Println("ERROR: invalid data format, no path found")
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+	rep, err := path.Resilience(ctx, res.Graph, sel, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+
+	fmt.Printf("intact (%s): %d turns on %d paths\n", sel.Algo, rep.Turns, len(sel.Paths))
+	for _, f := range rep.Failures {
+		switch {
+		case errors.Is(f.Err, path.ErrUnsatisfiable):
+			fmt.Printf("without %s: %v\n", f.Room, f.Err)
+			continue
+		case f.Disconnected():
+			fmt.Printf("without %s: no route from %s to %s\n", f.Room, res.Graph.Start.Name, res.Graph.End.Name)
+			continue
+		}
+		fmt.Printf("without %s: %d turns (%+d) on %d paths\n", f.Room, f.Turns, f.Impact, len(f.Paths))
+		for _, p := range f.Paths {
+			fmt.Printf("  %s\n", roomNames(p))
+		}
+	}
+}
+
+// roomNames renders a path as "a -> b -> c".
+func roomNames(p *model.Path) string {
+	names := make([]string, len(p.Rooms))
+	for i, r := range p.Rooms {
+		names[i] = r.Name
+	}
+	return strings.Join(names, " -> ")
+}
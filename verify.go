@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"lem-in/internal/parser"
+	"lem-in/internal/verify"
+)
+
+// runVerify referees a solution (echoed map plus moves) for a map: it prints
+// the turn count when every rule holds, or the first violation. On maps with
+// ##group lines each group's ants are held to that group's start and end.
+func runVerify(args []string) {
+	if len(args) != 2 {
+		fmt.Println(usage)
+		os.Exit(0)
+	}
+	res, err := parser.ParseFile(args[0])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(0)
+	}
+
+	var in io.Reader = os.Stdin
+	if args[1] != "-" {
+		f, err := os.Open(args[1])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	turns, err := verify.Groups(res.Graph, res.AntGroups(), in)
+	var v *verify.Violation
+	switch {
+	case errors.As(err, &v):
+		fmt.Println("INVALID:", v)
+		os.Exit(1)
+	case err != nil:
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("OK: %d turns\n", turns)
+}